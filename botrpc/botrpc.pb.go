@@ -10,8 +10,29 @@ It is generated from these files:
 
 It has these top-level messages:
 	Func
+	Schedule
 	FuncStatus
+	BotInfo
+	ConnectMessage
+	Registry
+	RegistryEntry
+	MessageClaim
+	AuditQuery
+	AuditRecord
+	TranscriptEntry
+	DescribeRequest
+	BotStatus
 	ChatMessage
+	Session
+	Reaction
+	Attachment
+	PostMessage
+	Integration
+	MiddlewareMessage
+	StorageKey
+	StorageItem
+	StorageIncrement
+	ChatEvent
 */
 package botrpc
 
@@ -35,6 +56,81 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// Capability is a feature that a chat platform or a bot may or may not
+// support. The router adapts messages to what the receiving peer declared.
+type Capability int32
+
+const (
+	Capability_UNKNOWN_CAPABILITY Capability = 0
+	Capability_RICH_CONTENT       Capability = 1
+	Capability_THREADS            Capability = 2
+	Capability_EPHEMERAL          Capability = 3
+	Capability_DIRECT_MESSAGES    Capability = 4
+	Capability_REACTIONS          Capability = 5
+	Capability_EVENTS             Capability = 6
+	// STREAMING_UPDATES integrations show updates by changing the response
+	// they replace. Others only get the last of a run of updates, once the
+	// func sends something else or is done.
+	Capability_STREAMING_UPDATES Capability = 7
+)
+
+var Capability_name = map[int32]string{
+	0: "UNKNOWN_CAPABILITY",
+	1: "RICH_CONTENT",
+	2: "THREADS",
+	3: "EPHEMERAL",
+	4: "DIRECT_MESSAGES",
+	5: "REACTIONS",
+	6: "EVENTS",
+	7: "STREAMING_UPDATES",
+}
+var Capability_value = map[string]int32{
+	"UNKNOWN_CAPABILITY": 0,
+	"RICH_CONTENT":       1,
+	"THREADS":            2,
+	"EPHEMERAL":          3,
+	"DIRECT_MESSAGES":    4,
+	"REACTIONS":          5,
+	"EVENTS":             6,
+	"STREAMING_UPDATES":  7,
+}
+
+func (x Capability) String() string {
+	return proto.EnumName(Capability_name, int32(x))
+}
+func (Capability) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+// TriggerType says how the trigger is matched against message bodies.
+type Func_TriggerType int32
+
+const (
+	Func_REGEX   Func_TriggerType = 0
+	Func_COMMAND Func_TriggerType = 1
+	Func_PREFIX  Func_TriggerType = 2
+	Func_KEYWORD Func_TriggerType = 3
+	Func_GLOB    Func_TriggerType = 4
+)
+
+var Func_TriggerType_name = map[int32]string{
+	0: "REGEX",
+	1: "COMMAND",
+	2: "PREFIX",
+	3: "KEYWORD",
+	4: "GLOB",
+}
+var Func_TriggerType_value = map[string]int32{
+	"REGEX":   0,
+	"COMMAND": 1,
+	"PREFIX":  2,
+	"KEYWORD": 3,
+	"GLOB":    4,
+}
+
+func (x Func_TriggerType) String() string {
+	return proto.EnumName(Func_TriggerType_name, int32(x))
+}
+func (Func_TriggerType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 0} }
+
 type FuncStatus_Status int32
 
 const (
@@ -54,13 +150,176 @@ var FuncStatus_Status_value = map[string]int32{
 func (x FuncStatus_Status) String() string {
 	return proto.EnumName(FuncStatus_Status_name, int32(x))
 }
-func (FuncStatus_Status) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1, 0} }
+func (FuncStatus_Status) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2, 0} }
+
+// ThreadReply lets a bot choose where its response is posted.
+type ChatMessage_ThreadReply int32
+
+const (
+	ChatMessage_SAME    ChatMessage_ThreadReply = 0
+	ChatMessage_THREAD  ChatMessage_ThreadReply = 1
+	ChatMessage_CHANNEL ChatMessage_ThreadReply = 2
+)
+
+var ChatMessage_ThreadReply_name = map[int32]string{
+	0: "SAME",
+	1: "THREAD",
+	2: "CHANNEL",
+}
+var ChatMessage_ThreadReply_value = map[string]int32{
+	"SAME":    0,
+	"THREAD":  1,
+	"CHANNEL": 2,
+}
+
+func (x ChatMessage_ThreadReply) String() string {
+	return proto.EnumName(ChatMessage_ThreadReply_name, int32(x))
+}
+func (ChatMessage_ThreadReply) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{13, 0} }
+
+// Visibility controls who can see a response. Integrations that can't
+// limit visibility post the response publicly.
+type ChatMessage_Visibility int32
+
+const (
+	ChatMessage_PUBLIC    ChatMessage_Visibility = 0
+	ChatMessage_EPHEMERAL ChatMessage_Visibility = 1
+	ChatMessage_DIRECT    ChatMessage_Visibility = 2
+)
+
+var ChatMessage_Visibility_name = map[int32]string{
+	0: "PUBLIC",
+	1: "EPHEMERAL",
+	2: "DIRECT",
+}
+var ChatMessage_Visibility_value = map[string]int32{
+	"PUBLIC":    0,
+	"EPHEMERAL": 1,
+	"DIRECT":    2,
+}
+
+func (x ChatMessage_Visibility) String() string {
+	return proto.EnumName(ChatMessage_Visibility_name, int32(x))
+}
+func (ChatMessage_Visibility) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{13, 1} }
+
+type Session_Action int32
+
+const (
+	Session_CONTINUE Session_Action = 0
+	Session_END      Session_Action = 1
+)
+
+var Session_Action_name = map[int32]string{
+	0: "CONTINUE",
+	1: "END",
+}
+var Session_Action_value = map[string]int32{
+	"CONTINUE": 0,
+	"END":      1,
+}
+
+func (x Session_Action) String() string {
+	return proto.EnumName(Session_Action_name, int32(x))
+}
+func (Session_Action) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{14, 0} }
+
+type Reaction_Action int32
+
+const (
+	Reaction_ADD    Reaction_Action = 0
+	Reaction_REMOVE Reaction_Action = 1
+)
+
+var Reaction_Action_name = map[int32]string{
+	0: "ADD",
+	1: "REMOVE",
+}
+var Reaction_Action_value = map[string]int32{
+	"ADD":    0,
+	"REMOVE": 1,
+}
+
+func (x Reaction_Action) String() string {
+	return proto.EnumName(Reaction_Action_name, int32(x))
+}
+func (Reaction_Action) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{15, 0} }
+
+type MiddlewareMessage_Direction int32
+
+const (
+	MiddlewareMessage_INBOUND  MiddlewareMessage_Direction = 0
+	MiddlewareMessage_OUTBOUND MiddlewareMessage_Direction = 1
+)
+
+var MiddlewareMessage_Direction_name = map[int32]string{
+	0: "INBOUND",
+	1: "OUTBOUND",
+}
+var MiddlewareMessage_Direction_value = map[string]int32{
+	"INBOUND":  0,
+	"OUTBOUND": 1,
+}
+
+func (x MiddlewareMessage_Direction) String() string {
+	return proto.EnumName(MiddlewareMessage_Direction_name, int32(x))
+}
+func (MiddlewareMessage_Direction) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{19, 0}
+}
+
+type ChatEvent_Type int32
+
+const (
+	ChatEvent_UNKNOWN          ChatEvent_Type = 0
+	ChatEvent_MEMBER_JOINED    ChatEvent_Type = 1
+	ChatEvent_MEMBER_LEFT      ChatEvent_Type = 2
+	ChatEvent_CHANNEL_CREATED  ChatEvent_Type = 3
+	ChatEvent_TOPIC_CHANGED    ChatEvent_Type = 4
+	ChatEvent_PRESENCE_CHANGED ChatEvent_Type = 5
+	ChatEvent_MESSAGE_EDITED   ChatEvent_Type = 6
+	ChatEvent_MESSAGE_DELETED  ChatEvent_Type = 7
+)
+
+var ChatEvent_Type_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "MEMBER_JOINED",
+	2: "MEMBER_LEFT",
+	3: "CHANNEL_CREATED",
+	4: "TOPIC_CHANGED",
+	5: "PRESENCE_CHANGED",
+	6: "MESSAGE_EDITED",
+	7: "MESSAGE_DELETED",
+}
+var ChatEvent_Type_value = map[string]int32{
+	"UNKNOWN":          0,
+	"MEMBER_JOINED":    1,
+	"MEMBER_LEFT":      2,
+	"CHANNEL_CREATED":  3,
+	"TOPIC_CHANGED":    4,
+	"PRESENCE_CHANGED": 5,
+	"MESSAGE_EDITED":   6,
+	"MESSAGE_DELETED":  7,
+}
+
+func (x ChatEvent_Type) String() string {
+	return proto.EnumName(ChatEvent_Type_name, int32(x))
+}
+func (ChatEvent_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{23, 0} }
 
 type Func struct {
-	Addr     string `protobuf:"bytes,1,opt,name=addr" json:"addr,omitempty"`
-	Trigger  string `protobuf:"bytes,2,opt,name=trigger" json:"trigger,omitempty"`
-	FuncName string `protobuf:"bytes,3,opt,name=func_name,json=funcName" json:"func_name,omitempty"`
-	Usage    string `protobuf:"bytes,4,opt,name=usage" json:"usage,omitempty"`
+	Addr            string           `protobuf:"bytes,1,opt,name=addr" json:"addr,omitempty"`
+	Trigger         string           `protobuf:"bytes,2,opt,name=trigger" json:"trigger,omitempty"`
+	FuncName        string           `protobuf:"bytes,3,opt,name=func_name,json=funcName" json:"func_name,omitempty"`
+	Usage           string           `protobuf:"bytes,4,opt,name=usage" json:"usage,omitempty"`
+	ReactionTrigger string           `protobuf:"bytes,5,opt,name=reaction_trigger,json=reactionTrigger" json:"reaction_trigger,omitempty"`
+	Events          []ChatEvent_Type `protobuf:"varint,6,rep,packed,name=events,enum=botrpc.ChatEvent_Type" json:"events,omitempty"`
+	Schedules       []*Schedule      `protobuf:"bytes,7,rep,name=schedules" json:"schedules,omitempty"`
+	Examples        []string         `protobuf:"bytes,8,rep,name=examples" json:"examples,omitempty"`
+	TriggerType     Func_TriggerType `protobuf:"varint,9,opt,name=trigger_type,json=triggerType,enum=botrpc.Func_TriggerType" json:"trigger_type,omitempty"`
+	IgnoreCase      bool             `protobuf:"varint,10,opt,name=ignore_case,json=ignoreCase" json:"ignore_case,omitempty"`
+	ProtocolVersion uint32           `protobuf:"varint,11,opt,name=protocol_version,json=protocolVersion" json:"protocol_version,omitempty"`
+	Capabilities    []Capability     `protobuf:"varint,12,rep,packed,name=capabilities,enum=botrpc.Capability" json:"capabilities,omitempty"`
 }
 
 func (m *Func) Reset()                    { *m = Func{} }
@@ -68,32 +327,440 @@ func (m *Func) String() string            { return proto.CompactTextString(m) }
 func (*Func) ProtoMessage()               {}
 func (*Func) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+// Schedule calls a BotFunc at the times matching a cron expression. The
+// BotFunc receives a ChatMessage with the body, channel and source of the
+// schedule and its responses are delivered to that channel.
+type Schedule struct {
+	Cron     string `protobuf:"bytes,1,opt,name=cron" json:"cron,omitempty"`
+	Timezone string `protobuf:"bytes,2,opt,name=timezone" json:"timezone,omitempty"`
+	Channel  string `protobuf:"bytes,3,opt,name=channel" json:"channel,omitempty"`
+	Source   string `protobuf:"bytes,4,opt,name=source" json:"source,omitempty"`
+	Body     string `protobuf:"bytes,5,opt,name=body" json:"body,omitempty"`
+}
+
+func (m *Schedule) Reset()                    { *m = Schedule{} }
+func (m *Schedule) String() string            { return proto.CompactTextString(m) }
+func (*Schedule) ProtoMessage()               {}
+func (*Schedule) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
 type FuncStatus struct {
-	Status FuncStatus_Status `protobuf:"varint,1,opt,name=status,enum=botrpc.FuncStatus_Status" json:"status,omitempty"`
+	Status          FuncStatus_Status `protobuf:"varint,1,opt,name=status,enum=botrpc.FuncStatus_Status" json:"status,omitempty"`
+	Token           string            `protobuf:"bytes,2,opt,name=token" json:"token,omitempty"`
+	Warnings        []string          `protobuf:"bytes,3,rep,name=warnings" json:"warnings,omitempty"`
+	ProtocolVersion uint32            `protobuf:"varint,4,opt,name=protocol_version,json=protocolVersion" json:"protocol_version,omitempty"`
 }
 
 func (m *FuncStatus) Reset()                    { *m = FuncStatus{} }
 func (m *FuncStatus) String() string            { return proto.CompactTextString(m) }
 func (*FuncStatus) ProtoMessage()               {}
-func (*FuncStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*FuncStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
-type ChatMessage struct {
-	Body     string `protobuf:"bytes,1,opt,name=body" json:"body,omitempty"`
+// BotInfo describes a bot and the funcs it provides.
+type BotInfo struct {
+	Name        string  `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Version     string  `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+	Owner       string  `protobuf:"bytes,3,opt,name=owner" json:"owner,omitempty"`
+	Description string  `protobuf:"bytes,4,opt,name=description" json:"description,omitempty"`
+	Funcs       []*Func `protobuf:"bytes,5,rep,name=funcs" json:"funcs,omitempty"`
+}
+
+func (m *BotInfo) Reset()                    { *m = BotInfo{} }
+func (m *BotInfo) String() string            { return proto.CompactTextString(m) }
+func (*BotInfo) ProtoMessage()               {}
+func (*BotInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+// ConnectMessage is sent both ways on a Connect stream.
+type ConnectMessage struct {
+	Bot     *BotInfo     `protobuf:"bytes,1,opt,name=bot" json:"bot,omitempty"`
+	Status  *BotStatus   `protobuf:"bytes,2,opt,name=status" json:"status,omitempty"`
+	CallId  uint64       `protobuf:"varint,3,opt,name=call_id,json=callId" json:"call_id,omitempty"`
+	Message *ChatMessage `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"`
+	End     bool         `protobuf:"varint,5,opt,name=end" json:"end,omitempty"`
+	Error   string       `protobuf:"bytes,6,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *ConnectMessage) Reset()                    { *m = ConnectMessage{} }
+func (m *ConnectMessage) String() string            { return proto.CompactTextString(m) }
+func (*ConnectMessage) ProtoMessage()               {}
+func (*ConnectMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *ConnectMessage) GetBot() *BotInfo {
+	if m != nil {
+		return m.Bot
+	}
+	return nil
+}
+
+func (m *ConnectMessage) GetStatus() *BotStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *ConnectMessage) GetMessage() *ChatMessage {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+// Registry contains the funcs known to a router instance.
+type Registry struct {
+	Instance string           `protobuf:"bytes,1,opt,name=instance" json:"instance,omitempty"`
+	Entries  []*RegistryEntry `protobuf:"bytes,2,rep,name=entries" json:"entries,omitempty"`
+}
+
+func (m *Registry) Reset()                    { *m = Registry{} }
+func (m *Registry) String() string            { return proto.CompactTextString(m) }
+func (*Registry) ProtoMessage()               {}
+func (*Registry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+// RegistryEntry is a single func added to one of the instances.
+type RegistryEntry struct {
+	Key      string   `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Version  int64    `protobuf:"varint,2,opt,name=version" json:"version,omitempty"`
+	Instance string   `protobuf:"bytes,3,opt,name=instance" json:"instance,omitempty"`
+	Bot      *BotInfo `protobuf:"bytes,4,opt,name=bot" json:"bot,omitempty"`
+	Token    string   `protobuf:"bytes,5,opt,name=token" json:"token,omitempty"`
+	Removed  bool     `protobuf:"varint,6,opt,name=removed" json:"removed,omitempty"`
+}
+
+func (m *RegistryEntry) Reset()                    { *m = RegistryEntry{} }
+func (m *RegistryEntry) String() string            { return proto.CompactTextString(m) }
+func (*RegistryEntry) ProtoMessage()               {}
+func (*RegistryEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *RegistryEntry) GetBot() *BotInfo {
+	if m != nil {
+		return m.Bot
+	}
+	return nil
+}
+
+type MessageClaim struct {
+	Key      string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Instance string `protobuf:"bytes,2,opt,name=instance" json:"instance,omitempty"`
+	Granted  bool   `protobuf:"varint,3,opt,name=granted" json:"granted,omitempty"`
+}
+
+func (m *MessageClaim) Reset()                    { *m = MessageClaim{} }
+func (m *MessageClaim) String() string            { return proto.CompactTextString(m) }
+func (*MessageClaim) ProtoMessage()               {}
+func (*MessageClaim) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+// AuditQuery selects audit records. Empty fields match every record.
+type AuditQuery struct {
+	Token    string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
 	User     string `protobuf:"bytes,2,opt,name=user" json:"user,omitempty"`
-	Channel  string `protobuf:"bytes,3,opt,name=channel" json:"channel,omitempty"`
-	FuncName string `protobuf:"bytes,4,opt,name=func_name,json=funcName" json:"func_name,omitempty"`
+	FuncName string `protobuf:"bytes,3,opt,name=func_name,json=funcName" json:"func_name,omitempty"`
+	Start    int64  `protobuf:"varint,4,opt,name=start" json:"start,omitempty"`
+	End      int64  `protobuf:"varint,5,opt,name=end" json:"end,omitempty"`
+}
+
+func (m *AuditQuery) Reset()                    { *m = AuditQuery{} }
+func (m *AuditQuery) String() string            { return proto.CompactTextString(m) }
+func (*AuditQuery) ProtoMessage()               {}
+func (*AuditQuery) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+// AuditRecord is the audit log entry for one message that called funcs.
+type AuditRecord struct {
+	Time       int64    `protobuf:"varint,1,opt,name=time" json:"time,omitempty"`
+	MessageId  string   `protobuf:"bytes,2,opt,name=message_id,json=messageId" json:"message_id,omitempty"`
+	Source     string   `protobuf:"bytes,3,opt,name=source" json:"source,omitempty"`
+	User       string   `protobuf:"bytes,4,opt,name=user" json:"user,omitempty"`
+	Channel    string   `protobuf:"bytes,5,opt,name=channel" json:"channel,omitempty"`
+	Funcs      []string `protobuf:"bytes,6,rep,name=funcs" json:"funcs,omitempty"`
+	Outcome    string   `protobuf:"bytes,7,opt,name=outcome" json:"outcome,omitempty"`
+	DurationMs int64    `protobuf:"varint,8,opt,name=duration_ms,json=durationMs" json:"duration_ms,omitempty"`
+	Error      string   `protobuf:"bytes,9,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *AuditRecord) Reset()                    { *m = AuditRecord{} }
+func (m *AuditRecord) String() string            { return proto.CompactTextString(m) }
+func (*AuditRecord) ProtoMessage()               {}
+func (*AuditRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+// TranscriptEntry is a message from an integration and the responses the
+// router sent back for it, as recorded in a transcript.
+type TranscriptEntry struct {
+	Time      int64          `protobuf:"varint,1,opt,name=time" json:"time,omitempty"`
+	Message   *ChatMessage   `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+	Responses []*ChatMessage `protobuf:"bytes,3,rep,name=responses" json:"responses,omitempty"`
+}
+
+func (m *TranscriptEntry) Reset()                    { *m = TranscriptEntry{} }
+func (m *TranscriptEntry) String() string            { return proto.CompactTextString(m) }
+func (*TranscriptEntry) ProtoMessage()               {}
+func (*TranscriptEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *TranscriptEntry) GetMessage() *ChatMessage {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+type DescribeRequest struct {
+	ProtocolVersion uint32 `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion" json:"protocol_version,omitempty"`
+}
+
+func (m *DescribeRequest) Reset()                    { *m = DescribeRequest{} }
+func (m *DescribeRequest) String() string            { return proto.CompactTextString(m) }
+func (*DescribeRequest) ProtoMessage()               {}
+func (*DescribeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+type BotStatus struct {
+	Status FuncStatus_Status `protobuf:"varint,1,opt,name=status,enum=botrpc.FuncStatus_Status" json:"status,omitempty"`
+	Funcs  []*FuncStatus     `protobuf:"bytes,2,rep,name=funcs" json:"funcs,omitempty"`
+}
+
+func (m *BotStatus) Reset()                    { *m = BotStatus{} }
+func (m *BotStatus) String() string            { return proto.CompactTextString(m) }
+func (*BotStatus) ProtoMessage()               {}
+func (*BotStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+type ChatMessage struct {
+	Body        string                  `protobuf:"bytes,1,opt,name=body" json:"body,omitempty"`
+	User        string                  `protobuf:"bytes,2,opt,name=user" json:"user,omitempty"`
+	Channel     string                  `protobuf:"bytes,3,opt,name=channel" json:"channel,omitempty"`
+	FuncName    string                  `protobuf:"bytes,4,opt,name=func_name,json=funcName" json:"func_name,omitempty"`
+	Attachments []*Attachment           `protobuf:"bytes,5,rep,name=attachments" json:"attachments,omitempty"`
+	ThreadId    string                  `protobuf:"bytes,6,opt,name=thread_id,json=threadId" json:"thread_id,omitempty"`
+	MessageId   string                  `protobuf:"bytes,7,opt,name=message_id,json=messageId" json:"message_id,omitempty"`
+	ThreadReply ChatMessage_ThreadReply `protobuf:"varint,8,opt,name=thread_reply,json=threadReply,enum=botrpc.ChatMessage_ThreadReply" json:"thread_reply,omitempty"`
+	Visibility  ChatMessage_Visibility  `protobuf:"varint,9,opt,name=visibility,enum=botrpc.ChatMessage_Visibility" json:"visibility,omitempty"`
+	Reaction    *Reaction               `protobuf:"bytes,10,opt,name=reaction" json:"reaction,omitempty"`
+	Event       *ChatEvent              `protobuf:"bytes,11,opt,name=event" json:"event,omitempty"`
+	Source      string                  `protobuf:"bytes,12,opt,name=source" json:"source,omitempty"`
+	Session     *Session                `protobuf:"bytes,13,opt,name=session" json:"session,omitempty"`
+	Input       string                  `protobuf:"bytes,14,opt,name=input" json:"input,omitempty"`
+	Addressed   bool                    `protobuf:"varint,15,opt,name=addressed" json:"addressed,omitempty"`
+	Update      bool                    `protobuf:"varint,16,opt,name=update" json:"update,omitempty"`
 }
 
 func (m *ChatMessage) Reset()                    { *m = ChatMessage{} }
 func (m *ChatMessage) String() string            { return proto.CompactTextString(m) }
 func (*ChatMessage) ProtoMessage()               {}
-func (*ChatMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*ChatMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ChatMessage) GetReaction() *Reaction {
+	if m != nil {
+		return m.Reaction
+	}
+	return nil
+}
+
+func (m *ChatMessage) GetEvent() *ChatEvent {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *ChatMessage) GetSession() *Session {
+	if m != nil {
+		return m.Session
+	}
+	return nil
+}
+
+// Session lets a BotFunc have a conversation with a user. A response with a
+// session opens or extends it and the user's following messages in the
+// channel go straight to the BotFunc until the session ends or times out.
+// Messages sent to a BotFunc during a session have the session set as well.
+type Session struct {
+	Action  Session_Action `protobuf:"varint,1,opt,name=action,enum=botrpc.Session_Action" json:"action,omitempty"`
+	Timeout int32          `protobuf:"varint,2,opt,name=timeout" json:"timeout,omitempty"`
+}
+
+func (m *Session) Reset()                    { *m = Session{} }
+func (m *Session) String() string            { return proto.CompactTextString(m) }
+func (*Session) ProtoMessage()               {}
+func (*Session) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+// Reaction is an emoji reaction to the message identified by the ChatMessage
+// message_id. Bots send them to react to messages and receive them when a
+// reaction_trigger matches.
+type Reaction struct {
+	Action Reaction_Action `protobuf:"varint,1,opt,name=action,enum=botrpc.Reaction_Action" json:"action,omitempty"`
+	Name   string          `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *Reaction) Reset()                    { *m = Reaction{} }
+func (m *Reaction) String() string            { return proto.CompactTextString(m) }
+func (*Reaction) ProtoMessage()               {}
+func (*Reaction) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+// Attachment is platform neutral rich content. Integrations that can't render
+// it natively should fall back to the plain text rendering.
+type Attachment struct {
+	Title     string              `protobuf:"bytes,1,opt,name=title" json:"title,omitempty"`
+	TitleLink string              `protobuf:"bytes,2,opt,name=title_link,json=titleLink" json:"title_link,omitempty"`
+	Text      string              `protobuf:"bytes,3,opt,name=text" json:"text,omitempty"`
+	Color     string              `protobuf:"bytes,4,opt,name=color" json:"color,omitempty"`
+	Fields    []*Attachment_Field `protobuf:"bytes,5,rep,name=fields" json:"fields,omitempty"`
+	ImageUrl  string              `protobuf:"bytes,6,opt,name=image_url,json=imageUrl" json:"image_url,omitempty"`
+	ThumbUrl  string              `protobuf:"bytes,7,opt,name=thumb_url,json=thumbUrl" json:"thumb_url,omitempty"`
+	Code      string              `protobuf:"bytes,8,opt,name=code" json:"code,omitempty"`
+	Markdown  bool                `protobuf:"varint,9,opt,name=markdown" json:"markdown,omitempty"`
+	Footer    string              `protobuf:"bytes,10,opt,name=footer" json:"footer,omitempty"`
+}
+
+func (m *Attachment) Reset()                    { *m = Attachment{} }
+func (m *Attachment) String() string            { return proto.CompactTextString(m) }
+func (*Attachment) ProtoMessage()               {}
+func (*Attachment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+type Attachment_Field struct {
+	Title string `protobuf:"bytes,1,opt,name=title" json:"title,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+	Short bool   `protobuf:"varint,3,opt,name=short" json:"short,omitempty"`
+}
+
+func (m *Attachment_Field) Reset()                    { *m = Attachment_Field{} }
+func (m *Attachment_Field) String() string            { return proto.CompactTextString(m) }
+func (*Attachment_Field) ProtoMessage()               {}
+func (*Attachment_Field) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16, 0} }
+
+type PostMessage struct {
+	Token   string       `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Message *ChatMessage `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *PostMessage) Reset()                    { *m = PostMessage{} }
+func (m *PostMessage) String() string            { return proto.CompactTextString(m) }
+func (*PostMessage) ProtoMessage()               {}
+func (*PostMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *PostMessage) GetMessage() *ChatMessage {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+type Integration struct {
+	Name            string       `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Workspace       string       `protobuf:"bytes,2,opt,name=workspace" json:"workspace,omitempty"`
+	Capabilities    []Capability `protobuf:"varint,3,rep,packed,name=capabilities,enum=botrpc.Capability" json:"capabilities,omitempty"`
+	Id              string       `protobuf:"bytes,4,opt,name=id" json:"id,omitempty"`
+	ProtocolVersion uint32       `protobuf:"varint,5,opt,name=protocol_version,json=protocolVersion" json:"protocol_version,omitempty"`
+}
+
+func (m *Integration) Reset()                    { *m = Integration{} }
+func (m *Integration) String() string            { return proto.CompactTextString(m) }
+func (*Integration) ProtoMessage()               {}
+func (*Integration) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+type MiddlewareMessage struct {
+	Direction MiddlewareMessage_Direction `protobuf:"varint,1,opt,name=direction,enum=botrpc.MiddlewareMessage_Direction" json:"direction,omitempty"`
+	Message   *ChatMessage                `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+	Request   *ChatMessage                `protobuf:"bytes,3,opt,name=request" json:"request,omitempty"`
+}
+
+func (m *MiddlewareMessage) Reset()                    { *m = MiddlewareMessage{} }
+func (m *MiddlewareMessage) String() string            { return proto.CompactTextString(m) }
+func (*MiddlewareMessage) ProtoMessage()               {}
+func (*MiddlewareMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *MiddlewareMessage) GetMessage() *ChatMessage {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (m *MiddlewareMessage) GetRequest() *ChatMessage {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+type StorageKey struct {
+	Token string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Key   string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+}
+
+func (m *StorageKey) Reset()                    { *m = StorageKey{} }
+func (m *StorageKey) String() string            { return proto.CompactTextString(m) }
+func (*StorageKey) ProtoMessage()               {}
+func (*StorageKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+type StorageItem struct {
+	Token string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Key   string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Value []byte `protobuf:"bytes,3,opt,name=value" json:"value,omitempty"`
+	Ttl   int64  `protobuf:"varint,4,opt,name=ttl" json:"ttl,omitempty"`
+}
+
+func (m *StorageItem) Reset()                    { *m = StorageItem{} }
+func (m *StorageItem) String() string            { return proto.CompactTextString(m) }
+func (*StorageItem) ProtoMessage()               {}
+func (*StorageItem) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+type StorageIncrement struct {
+	Token string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	Key   string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Delta int64  `protobuf:"varint,3,opt,name=delta" json:"delta,omitempty"`
+	Ttl   int64  `protobuf:"varint,4,opt,name=ttl" json:"ttl,omitempty"`
+}
+
+func (m *StorageIncrement) Reset()                    { *m = StorageIncrement{} }
+func (m *StorageIncrement) String() string            { return proto.CompactTextString(m) }
+func (*StorageIncrement) ProtoMessage()               {}
+func (*StorageIncrement) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+// ChatEvent is something that happened in the chat other than a message. The
+// user, channel and message_id of the ChatMessage carrying the event say who
+// caused it, where and to which message.
+type ChatEvent struct {
+	Type         ChatEvent_Type `protobuf:"varint,1,opt,name=type,enum=botrpc.ChatEvent_Type" json:"type,omitempty"`
+	Text         string         `protobuf:"bytes,2,opt,name=text" json:"text,omitempty"`
+	PreviousText string         `protobuf:"bytes,3,opt,name=previous_text,json=previousText" json:"previous_text,omitempty"`
+}
+
+func (m *ChatEvent) Reset()                    { *m = ChatEvent{} }
+func (m *ChatEvent) String() string            { return proto.CompactTextString(m) }
+func (*ChatEvent) ProtoMessage()               {}
+func (*ChatEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func init() {
 	proto.RegisterType((*Func)(nil), "botrpc.Func")
+	proto.RegisterType((*Schedule)(nil), "botrpc.Schedule")
 	proto.RegisterType((*FuncStatus)(nil), "botrpc.FuncStatus")
+	proto.RegisterType((*BotInfo)(nil), "botrpc.BotInfo")
+	proto.RegisterType((*ConnectMessage)(nil), "botrpc.ConnectMessage")
+	proto.RegisterType((*Registry)(nil), "botrpc.Registry")
+	proto.RegisterType((*RegistryEntry)(nil), "botrpc.RegistryEntry")
+	proto.RegisterType((*MessageClaim)(nil), "botrpc.MessageClaim")
+	proto.RegisterType((*AuditQuery)(nil), "botrpc.AuditQuery")
+	proto.RegisterType((*AuditRecord)(nil), "botrpc.AuditRecord")
+	proto.RegisterType((*TranscriptEntry)(nil), "botrpc.TranscriptEntry")
+	proto.RegisterType((*DescribeRequest)(nil), "botrpc.DescribeRequest")
+	proto.RegisterType((*BotStatus)(nil), "botrpc.BotStatus")
 	proto.RegisterType((*ChatMessage)(nil), "botrpc.ChatMessage")
+	proto.RegisterType((*Session)(nil), "botrpc.Session")
+	proto.RegisterType((*Reaction)(nil), "botrpc.Reaction")
+	proto.RegisterType((*Attachment)(nil), "botrpc.Attachment")
+	proto.RegisterType((*Attachment_Field)(nil), "botrpc.Attachment.Field")
+	proto.RegisterType((*PostMessage)(nil), "botrpc.PostMessage")
+	proto.RegisterType((*Integration)(nil), "botrpc.Integration")
+	proto.RegisterType((*MiddlewareMessage)(nil), "botrpc.MiddlewareMessage")
+	proto.RegisterType((*StorageKey)(nil), "botrpc.StorageKey")
+	proto.RegisterType((*StorageItem)(nil), "botrpc.StorageItem")
+	proto.RegisterType((*StorageIncrement)(nil), "botrpc.StorageIncrement")
+	proto.RegisterType((*ChatEvent)(nil), "botrpc.ChatEvent")
+	proto.RegisterEnum("botrpc.Capability", Capability_name, Capability_value)
+	proto.RegisterEnum("botrpc.Func_TriggerType", Func_TriggerType_name, Func_TriggerType_value)
 	proto.RegisterEnum("botrpc.FuncStatus_Status", FuncStatus_Status_name, FuncStatus_Status_value)
+	proto.RegisterEnum("botrpc.ChatMessage_ThreadReply", ChatMessage_ThreadReply_name, ChatMessage_ThreadReply_value)
+	proto.RegisterEnum("botrpc.ChatMessage_Visibility", ChatMessage_Visibility_name, ChatMessage_Visibility_value)
+	proto.RegisterEnum("botrpc.Session_Action", Session_Action_name, Session_Action_value)
+	proto.RegisterEnum("botrpc.Reaction_Action", Reaction_Action_name, Reaction_Action_value)
+	proto.RegisterEnum("botrpc.MiddlewareMessage_Direction", MiddlewareMessage_Direction_name, MiddlewareMessage_Direction_value)
+	proto.RegisterEnum("botrpc.ChatEvent_Type", ChatEvent_Type_name, ChatEvent_Type_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -109,10 +776,35 @@ const _ = grpc.SupportPackageIsVersion2
 type BotClient interface {
 	// Register registers a function service to the bot. Information about
 	// the registration including the success is returned in the
-	// Registration.
+	// Registration. A func rejected for conflicting with others gets the
+	// ERROR status with the conflicts as warnings instead of an error.
 	Add(ctx context.Context, in *Func, opts ...grpc.CallOption) (*FuncStatus, error)
+	// Remove removes the func with the addr and func_name of Func.
 	Remove(ctx context.Context, in *Func, opts ...grpc.CallOption) (*FuncStatus, error)
+	// RegisterBot adds all the funcs of a bot at once. Either all of them are
+	// added or none are. Registering a bot again replaces its funcs. Bots
+	// rejected for conflicts get the ERROR status and the conflicts as
+	// warnings of the funcs causing them instead of an error.
+	RegisterBot(ctx context.Context, in *BotInfo, opts ...grpc.CallOption) (*BotStatus, error)
+	// RemoveBot removes the bot with the name of BotInfo and all its funcs.
+	RemoveBot(ctx context.Context, in *BotInfo, opts ...grpc.CallOption) (*BotStatus, error)
+	// Connect is called by bots the router can't dial. The bot sends its
+	// BotInfo first and gets the BotStatus back. After that the router sends
+	// a call for every message that triggers one of the funcs and the bot
+	// answers with the responses for the call followed by one that ends it.
+	// The bot is removed when the stream closes.
+	Connect(ctx context.Context, opts ...grpc.CallOption) (Bot_ConnectClient, error)
 	SendMessage(ctx context.Context, in *ChatMessage, opts ...grpc.CallOption) (Bot_SendMessageClient, error)
+	// Post sends a message from a bot to a channel without waiting for a
+	// message to respond to. The token is the one returned by Add.
+	Post(ctx context.Context, in *PostMessage, opts ...grpc.CallOption) (*FuncStatus, error)
+	// Register tells the router about an integration. The returned
+	// Integration has its id set, which the integration uses as the source of
+	// its messages, and the protocol version the router will speak with it.
+	Register(ctx context.Context, in *Integration, opts ...grpc.CallOption) (*Integration, error)
+	// Subscribe is called by integrations to receive the messages bots Post
+	// and messages sent to them from other integrations.
+	Subscribe(ctx context.Context, in *Integration, opts ...grpc.CallOption) (Bot_SubscribeClient, error)
 }
 
 type botClient struct {
@@ -141,8 +833,57 @@ func (c *botClient) Remove(ctx context.Context, in *Func, opts ...grpc.CallOptio
 	return out, nil
 }
 
+func (c *botClient) RegisterBot(ctx context.Context, in *BotInfo, opts ...grpc.CallOption) (*BotStatus, error) {
+	out := new(BotStatus)
+	err := grpc.Invoke(ctx, "/botrpc.Bot/RegisterBot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botClient) RemoveBot(ctx context.Context, in *BotInfo, opts ...grpc.CallOption) (*BotStatus, error) {
+	out := new(BotStatus)
+	err := grpc.Invoke(ctx, "/botrpc.Bot/RemoveBot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botClient) Connect(ctx context.Context, opts ...grpc.CallOption) (Bot_ConnectClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Bot_serviceDesc.Streams[0], c.cc, "/botrpc.Bot/Connect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &botConnectClient{stream}
+	return x, nil
+}
+
+type Bot_ConnectClient interface {
+	Send(*ConnectMessage) error
+	Recv() (*ConnectMessage, error)
+	grpc.ClientStream
+}
+
+type botConnectClient struct {
+	grpc.ClientStream
+}
+
+func (x *botConnectClient) Send(m *ConnectMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *botConnectClient) Recv() (*ConnectMessage, error) {
+	m := new(ConnectMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *botClient) SendMessage(ctx context.Context, in *ChatMessage, opts ...grpc.CallOption) (Bot_SendMessageClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_Bot_serviceDesc.Streams[0], c.cc, "/botrpc.Bot/SendMessage", opts...)
+	stream, err := grpc.NewClientStream(ctx, &_Bot_serviceDesc.Streams[1], c.cc, "/botrpc.Bot/SendMessage", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -173,15 +914,90 @@ func (x *botSendMessageClient) Recv() (*ChatMessage, error) {
 	return m, nil
 }
 
+func (c *botClient) Post(ctx context.Context, in *PostMessage, opts ...grpc.CallOption) (*FuncStatus, error) {
+	out := new(FuncStatus)
+	err := grpc.Invoke(ctx, "/botrpc.Bot/Post", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botClient) Register(ctx context.Context, in *Integration, opts ...grpc.CallOption) (*Integration, error) {
+	out := new(Integration)
+	err := grpc.Invoke(ctx, "/botrpc.Bot/Register", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *botClient) Subscribe(ctx context.Context, in *Integration, opts ...grpc.CallOption) (Bot_SubscribeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Bot_serviceDesc.Streams[2], c.cc, "/botrpc.Bot/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &botSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Bot_SubscribeClient interface {
+	Recv() (*ChatMessage, error)
+	grpc.ClientStream
+}
+
+type botSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *botSubscribeClient) Recv() (*ChatMessage, error) {
+	m := new(ChatMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for Bot service
 
 type BotServer interface {
 	// Register registers a function service to the bot. Information about
 	// the registration including the success is returned in the
-	// Registration.
+	// Registration. A func rejected for conflicting with others gets the
+	// ERROR status with the conflicts as warnings instead of an error.
 	Add(context.Context, *Func) (*FuncStatus, error)
+	// Remove removes the func with the addr and func_name of Func.
 	Remove(context.Context, *Func) (*FuncStatus, error)
+	// RegisterBot adds all the funcs of a bot at once. Either all of them are
+	// added or none are. Registering a bot again replaces its funcs. Bots
+	// rejected for conflicts get the ERROR status and the conflicts as
+	// warnings of the funcs causing them instead of an error.
+	RegisterBot(context.Context, *BotInfo) (*BotStatus, error)
+	// RemoveBot removes the bot with the name of BotInfo and all its funcs.
+	RemoveBot(context.Context, *BotInfo) (*BotStatus, error)
+	// Connect is called by bots the router can't dial. The bot sends its
+	// BotInfo first and gets the BotStatus back. After that the router sends
+	// a call for every message that triggers one of the funcs and the bot
+	// answers with the responses for the call followed by one that ends it.
+	// The bot is removed when the stream closes.
+	Connect(Bot_ConnectServer) error
 	SendMessage(*ChatMessage, Bot_SendMessageServer) error
+	// Post sends a message from a bot to a channel without waiting for a
+	// message to respond to. The token is the one returned by Add.
+	Post(context.Context, *PostMessage) (*FuncStatus, error)
+	// Register tells the router about an integration. The returned
+	// Integration has its id set, which the integration uses as the source of
+	// its messages, and the protocol version the router will speak with it.
+	Register(context.Context, *Integration) (*Integration, error)
+	// Subscribe is called by integrations to receive the messages bots Post
+	// and messages sent to them from other integrations.
+	Subscribe(*Integration, Bot_SubscribeServer) error
 }
 
 func RegisterBotServer(s *grpc.Server, srv BotServer) {
@@ -224,11 +1040,73 @@ func _Bot_Remove_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Bot_SendMessage_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(ChatMessage)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
+func _Bot_RegisterBot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BotInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotServer).RegisterBot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Bot/RegisterBot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotServer).RegisterBot(ctx, req.(*BotInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bot_RemoveBot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BotInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotServer).RemoveBot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Bot/RemoveBot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotServer).RemoveBot(ctx, req.(*BotInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bot_Connect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BotServer).Connect(&botConnectServer{stream})
+}
+
+type Bot_ConnectServer interface {
+	Send(*ConnectMessage) error
+	Recv() (*ConnectMessage, error)
+	grpc.ServerStream
+}
+
+type botConnectServer struct {
+	grpc.ServerStream
+}
+
+func (x *botConnectServer) Send(m *ConnectMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *botConnectServer) Recv() (*ConnectMessage, error) {
+	m := new(ConnectMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Bot_SendMessage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChatMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
 	return srv.(BotServer).SendMessage(m, &botSendMessageServer{stream})
 }
 
@@ -245,6 +1123,63 @@ func (x *botSendMessageServer) Send(m *ChatMessage) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Bot_Post_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotServer).Post(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Bot/Post",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotServer).Post(ctx, req.(*PostMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bot_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Integration)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Bot/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotServer).Register(ctx, req.(*Integration))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bot_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Integration)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BotServer).Subscribe(m, &botSubscribeServer{stream})
+}
+
+type Bot_SubscribeServer interface {
+	Send(*ChatMessage) error
+	grpc.ServerStream
+}
+
+type botSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *botSubscribeServer) Send(m *ChatMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Bot_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "botrpc.Bot",
 	HandlerType: (*BotServer)(nil),
@@ -257,13 +1192,40 @@ var _Bot_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Remove",
 			Handler:    _Bot_Remove_Handler,
 		},
+		{
+			MethodName: "RegisterBot",
+			Handler:    _Bot_RegisterBot_Handler,
+		},
+		{
+			MethodName: "RemoveBot",
+			Handler:    _Bot_RemoveBot_Handler,
+		},
+		{
+			MethodName: "Post",
+			Handler:    _Bot_Post_Handler,
+		},
+		{
+			MethodName: "Register",
+			Handler:    _Bot_Register_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Connect",
+			Handler:       _Bot_Connect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "SendMessage",
 			Handler:       _Bot_SendMessage_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Bot_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
 }
 
@@ -271,6 +1233,10 @@ var _Bot_serviceDesc = grpc.ServiceDesc{
 
 type BotFuncsClient interface {
 	SendMessage(ctx context.Context, in *ChatMessage, opts ...grpc.CallOption) (BotFuncs_SendMessageClient, error)
+	// Describe returns the bot and its funcs so the router can add bots it
+	// discovered itself. Funcs without an addr are called at the address the
+	// bot was discovered at.
+	Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*BotInfo, error)
 }
 
 type botFuncsClient struct {
@@ -313,10 +1279,23 @@ func (x *botFuncsSendMessageClient) Recv() (*ChatMessage, error) {
 	return m, nil
 }
 
+func (c *botFuncsClient) Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*BotInfo, error) {
+	out := new(BotInfo)
+	err := grpc.Invoke(ctx, "/botrpc.BotFuncs/Describe", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for BotFuncs service
 
 type BotFuncsServer interface {
 	SendMessage(*ChatMessage, BotFuncs_SendMessageServer) error
+	// Describe returns the bot and its funcs so the router can add bots it
+	// discovered itself. Funcs without an addr are called at the address the
+	// bot was discovered at.
+	Describe(context.Context, *DescribeRequest) (*BotInfo, error)
 }
 
 func RegisterBotFuncsServer(s *grpc.Server, srv BotFuncsServer) {
@@ -344,10 +1323,33 @@ func (x *botFuncsSendMessageServer) Send(m *ChatMessage) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _BotFuncs_Describe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotFuncsServer).Describe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.BotFuncs/Describe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotFuncsServer).Describe(ctx, req.(*DescribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _BotFuncs_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "botrpc.BotFuncs",
 	HandlerType: (*BotFuncsServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Describe",
+			Handler:    _BotFuncs_Describe_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "SendMessage",
@@ -357,25 +1359,677 @@ var _BotFuncs_serviceDesc = grpc.ServiceDesc{
 	},
 }
 
+// Client API for Storage service
+
+type StorageClient interface {
+	Get(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (*StorageItem, error)
+	Set(ctx context.Context, in *StorageItem, opts ...grpc.CallOption) (*StorageItem, error)
+	Delete(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (*StorageItem, error)
+	// List streams all the items with keys starting with the key.
+	List(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (Storage_ListClient, error)
+	// Increment adds delta to the integer stored at key, missing keys start at
+	// zero.
+	Increment(ctx context.Context, in *StorageIncrement, opts ...grpc.CallOption) (*StorageItem, error)
+}
+
+type storageClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewStorageClient(cc *grpc.ClientConn) StorageClient {
+	return &storageClient{cc}
+}
+
+func (c *storageClient) Get(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (*StorageItem, error) {
+	out := new(StorageItem)
+	err := grpc.Invoke(ctx, "/botrpc.Storage/Get", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) Set(ctx context.Context, in *StorageItem, opts ...grpc.CallOption) (*StorageItem, error) {
+	out := new(StorageItem)
+	err := grpc.Invoke(ctx, "/botrpc.Storage/Set", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) Delete(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (*StorageItem, error) {
+	out := new(StorageItem)
+	err := grpc.Invoke(ctx, "/botrpc.Storage/Delete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) List(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (Storage_ListClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Storage_serviceDesc.Streams[0], c.cc, "/botrpc.Storage/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Storage_ListClient interface {
+	Recv() (*StorageItem, error)
+	grpc.ClientStream
+}
+
+type storageListClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageListClient) Recv() (*StorageItem, error) {
+	m := new(StorageItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageClient) Increment(ctx context.Context, in *StorageIncrement, opts ...grpc.CallOption) (*StorageItem, error) {
+	out := new(StorageItem)
+	err := grpc.Invoke(ctx, "/botrpc.Storage/Increment", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Storage service
+
+type StorageServer interface {
+	Get(context.Context, *StorageKey) (*StorageItem, error)
+	Set(context.Context, *StorageItem) (*StorageItem, error)
+	Delete(context.Context, *StorageKey) (*StorageItem, error)
+	// List streams all the items with keys starting with the key.
+	List(*StorageKey, Storage_ListServer) error
+	// Increment adds delta to the integer stored at key, missing keys start at
+	// zero.
+	Increment(context.Context, *StorageIncrement) (*StorageItem, error)
+}
+
+func RegisterStorageServer(s *grpc.Server, srv StorageServer) {
+	s.RegisterService(&_Storage_serviceDesc, srv)
+}
+
+func _Storage_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Storage/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Get(ctx, req.(*StorageKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageItem)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Set(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Storage/Set",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Set(ctx, req.(*StorageItem))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Storage/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Delete(ctx, req.(*StorageKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StorageKey)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageServer).List(m, &storageListServer{stream})
+}
+
+type Storage_ListServer interface {
+	Send(*StorageItem) error
+	grpc.ServerStream
+}
+
+type storageListServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageListServer) Send(m *StorageItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Storage_Increment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageIncrement)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Increment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Storage/Increment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Increment(ctx, req.(*StorageIncrement))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Storage_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "botrpc.Storage",
+	HandlerType: (*StorageServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Storage_Get_Handler,
+		},
+		{
+			MethodName: "Set",
+			Handler:    _Storage_Set_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Storage_Delete_Handler,
+		},
+		{
+			MethodName: "Increment",
+			Handler:    _Storage_Increment_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _Storage_List_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// Client API for Cluster service
+
+type ClusterClient interface {
+	// Sync sends the registry of an instance and returns the registry of the
+	// instance called. Both keep the newest version of every entry.
+	Sync(ctx context.Context, in *Registry, opts ...grpc.CallOption) (*Registry, error)
+	// Claim asks the instance owning a message whether it may be handled.
+	// Only the first claim of a message is granted.
+	Claim(ctx context.Context, in *MessageClaim, opts ...grpc.CallOption) (*MessageClaim, error)
+	// Deliver sends a message to the integrations subscribed to the instance
+	// called.
+	Deliver(ctx context.Context, in *ChatMessage, opts ...grpc.CallOption) (*FuncStatus, error)
+}
+
+type clusterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewClusterClient(cc *grpc.ClientConn) ClusterClient {
+	return &clusterClient{cc}
+}
+
+func (c *clusterClient) Sync(ctx context.Context, in *Registry, opts ...grpc.CallOption) (*Registry, error) {
+	out := new(Registry)
+	err := grpc.Invoke(ctx, "/botrpc.Cluster/Sync", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) Claim(ctx context.Context, in *MessageClaim, opts ...grpc.CallOption) (*MessageClaim, error) {
+	out := new(MessageClaim)
+	err := grpc.Invoke(ctx, "/botrpc.Cluster/Claim", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) Deliver(ctx context.Context, in *ChatMessage, opts ...grpc.CallOption) (*FuncStatus, error) {
+	out := new(FuncStatus)
+	err := grpc.Invoke(ctx, "/botrpc.Cluster/Deliver", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Cluster service
+
+type ClusterServer interface {
+	// Sync sends the registry of an instance and returns the registry of the
+	// instance called. Both keep the newest version of every entry.
+	Sync(context.Context, *Registry) (*Registry, error)
+	// Claim asks the instance owning a message whether it may be handled.
+	// Only the first claim of a message is granted.
+	Claim(context.Context, *MessageClaim) (*MessageClaim, error)
+	// Deliver sends a message to the integrations subscribed to the instance
+	// called.
+	Deliver(context.Context, *ChatMessage) (*FuncStatus, error)
+}
+
+func RegisterClusterServer(s *grpc.Server, srv ClusterServer) {
+	s.RegisterService(&_Cluster_serviceDesc, srv)
+}
+
+func _Cluster_Sync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Registry)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Sync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Cluster/Sync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Sync(ctx, req.(*Registry))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_Claim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessageClaim)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Claim(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Cluster/Claim",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Claim(ctx, req.(*MessageClaim))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_Deliver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Deliver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Cluster/Deliver",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Deliver(ctx, req.(*ChatMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Cluster_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "botrpc.Cluster",
+	HandlerType: (*ClusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Sync",
+			Handler:    _Cluster_Sync_Handler,
+		},
+		{
+			MethodName: "Claim",
+			Handler:    _Cluster_Claim_Handler,
+		},
+		{
+			MethodName: "Deliver",
+			Handler:    _Cluster_Deliver_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// Client API for Admin service
+
+type AdminClient interface {
+	// QueryAudit streams the audit records matching the query, oldest first.
+	QueryAudit(ctx context.Context, in *AuditQuery, opts ...grpc.CallOption) (Admin_QueryAuditClient, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) QueryAudit(ctx context.Context, in *AuditQuery, opts ...grpc.CallOption) (Admin_QueryAuditClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Admin_serviceDesc.Streams[0], c.cc, "/botrpc.Admin/QueryAudit", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminQueryAuditClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_QueryAuditClient interface {
+	Recv() (*AuditRecord, error)
+	grpc.ClientStream
+}
+
+type adminQueryAuditClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminQueryAuditClient) Recv() (*AuditRecord, error) {
+	m := new(AuditRecord)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Admin service
+
+type AdminServer interface {
+	// QueryAudit streams the audit records matching the query, oldest first.
+	QueryAudit(*AuditQuery, Admin_QueryAuditServer) error
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_QueryAudit_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AuditQuery)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).QueryAudit(m, &adminQueryAuditServer{stream})
+}
+
+type Admin_QueryAuditServer interface {
+	Send(*AuditRecord) error
+	grpc.ServerStream
+}
+
+type adminQueryAuditServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminQueryAuditServer) Send(m *AuditRecord) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "botrpc.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "QueryAudit",
+			Handler:       _Admin_QueryAudit_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// Client API for Middleware service
+
+type MiddlewareClient interface {
+	Process(ctx context.Context, in *MiddlewareMessage, opts ...grpc.CallOption) (*MiddlewareMessage, error)
+}
+
+type middlewareClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMiddlewareClient(cc *grpc.ClientConn) MiddlewareClient {
+	return &middlewareClient{cc}
+}
+
+func (c *middlewareClient) Process(ctx context.Context, in *MiddlewareMessage, opts ...grpc.CallOption) (*MiddlewareMessage, error) {
+	out := new(MiddlewareMessage)
+	err := grpc.Invoke(ctx, "/botrpc.Middleware/Process", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Middleware service
+
+type MiddlewareServer interface {
+	Process(context.Context, *MiddlewareMessage) (*MiddlewareMessage, error)
+}
+
+func RegisterMiddlewareServer(s *grpc.Server, srv MiddlewareServer) {
+	s.RegisterService(&_Middleware_serviceDesc, srv)
+}
+
+func _Middleware_Process_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MiddlewareMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MiddlewareServer).Process(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/botrpc.Middleware/Process",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MiddlewareServer).Process(ctx, req.(*MiddlewareMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Middleware_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "botrpc.Middleware",
+	HandlerType: (*MiddlewareServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Process",
+			Handler:    _Middleware_Process_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
 var fileDescriptor0 = []byte{
-	// 299 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x92, 0xc1, 0x4b, 0xc3, 0x30,
-	0x14, 0xc6, 0xd7, 0xad, 0x8b, 0xeb, 0xdb, 0x90, 0xf1, 0xf4, 0x50, 0xb7, 0x8b, 0xe4, 0xa4, 0x20,
-	0x45, 0xeb, 0xd1, 0x93, 0x8a, 0x7a, 0x10, 0x1d, 0x64, 0x57, 0x41, 0xd2, 0x26, 0x76, 0xc2, 0xd6,
-	0x8c, 0x26, 0x15, 0xfc, 0x5b, 0xfc, 0x67, 0x6d, 0xd2, 0x56, 0x37, 0xf4, 0x20, 0x78, 0xea, 0xf7,
-	0x7d, 0xf9, 0x92, 0xf7, 0x7e, 0x50, 0x18, 0x25, 0xca, 0x14, 0xeb, 0x34, 0x5a, 0x17, 0xca, 0x28,
-	0x24, 0xb5, 0xa3, 0x19, 0xf8, 0xb7, 0x65, 0x9e, 0x22, 0x82, 0xcf, 0x85, 0x28, 0x42, 0xef, 0xd0,
-	0x3b, 0x0a, 0x98, 0xd3, 0x18, 0xc2, 0x8e, 0x29, 0x5e, 0xb3, 0x4c, 0x16, 0x61, 0xd7, 0xc5, 0xad,
-	0xc5, 0x29, 0x04, 0x2f, 0xd5, 0xad, 0xe7, 0x9c, 0xaf, 0x64, 0xd8, 0x73, 0x67, 0x03, 0x1b, 0x3c,
-	0x56, 0x1e, 0xf7, 0xa1, 0x5f, 0x6a, 0x9e, 0xc9, 0xd0, 0x77, 0x07, 0xb5, 0xa1, 0x4f, 0x00, 0x76,
-	0xd0, 0xdc, 0x70, 0x53, 0x6a, 0x3c, 0x03, 0xa2, 0x9d, 0x72, 0x03, 0x77, 0xe3, 0x83, 0xa8, 0xd9,
-	0xee, 0xbb, 0x13, 0xd5, 0x1f, 0xd6, 0x14, 0xe9, 0x14, 0x48, 0x73, 0x39, 0x80, 0xfe, 0x0d, 0x63,
-	0x33, 0x36, 0xee, 0x20, 0x81, 0xee, 0xec, 0x7e, 0xec, 0xd1, 0x25, 0x0c, 0xaf, 0x17, 0xdc, 0x3c,
-	0x48, 0x6d, 0x87, 0x59, 0x9a, 0x44, 0x89, 0xf7, 0x96, 0xc6, 0x6a, 0x9b, 0x95, 0xfa, 0x0b, 0xc5,
-	0x69, 0x4b, 0x98, 0x2e, 0x78, 0x9e, 0xcb, 0x65, 0x43, 0xd1, 0xda, 0x6d, 0x42, 0x7f, 0x9b, 0x30,
-	0xfe, 0xf0, 0xa0, 0x77, 0xa5, 0x0c, 0x1e, 0x43, 0xef, 0x52, 0x08, 0x1c, 0x6d, 0x2e, 0x3f, 0xc1,
-	0x9f, 0x28, 0xb4, 0x83, 0x27, 0x40, 0x98, 0x5c, 0xa9, 0x37, 0xf9, 0xa7, 0xf6, 0x05, 0x0c, 0xe7,
-	0x32, 0x17, 0x2d, 0xce, 0x5e, 0x5b, 0xda, 0x60, 0x9c, 0xfc, 0x16, 0xd2, 0xce, 0xa9, 0x17, 0xdf,
-	0xc1, 0xa0, 0x5a, 0xce, 0xbe, 0xa7, 0xff, 0xf5, 0x50, 0x42, 0xdc, 0xaf, 0x72, 0xfe, 0x19, 0x00,
-	0x00, 0xff, 0xff, 0xac, 0x84, 0x37, 0x9d, 0x3a, 0x02, 0x00, 0x00,
+	// 2323 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x58, 0x4d, 0x6f, 0xe3, 0xc6,
+	0xf9, 0x17, 0x45, 0x49, 0x94, 0x1e, 0xc9, 0x36, 0x77, 0x76, 0xb3, 0x61, 0x94, 0xe4, 0x1f, 0xff,
+	0x19, 0xa0, 0x75, 0x82, 0x8d, 0xe2, 0xd5, 0x6e, 0x17, 0x08, 0x12, 0xb4, 0x90, 0x25, 0xae, 0x57,
+	0x5d, 0x4b, 0x72, 0x47, 0xf2, 0x6e, 0x72, 0x28, 0x04, 0x5a, 0x9c, 0xb5, 0x09, 0x4b, 0xa4, 0x4a,
+	0x0e, 0xbd, 0xeb, 0xf6, 0xd0, 0xde, 0x7a, 0x2e, 0x0a, 0x14, 0xc8, 0xa5, 0xa7, 0x9e, 0x7a, 0x29,
+	0xd0, 0x5b, 0xbf, 0x44, 0x3f, 0x41, 0x3f, 0x41, 0x80, 0x7e, 0x87, 0x62, 0xde, 0x48, 0x4a, 0x96,
+	0x13, 0x6f, 0x7b, 0xd2, 0x3c, 0x2f, 0x33, 0xcf, 0xa3, 0x67, 0x7e, 0xcf, 0xcb, 0x10, 0x1a, 0xa7,
+	0x21, 0x8d, 0x96, 0xb3, 0xd6, 0x32, 0x0a, 0x69, 0x88, 0x2a, 0x82, 0xb2, 0xbf, 0x2d, 0x41, 0xe9,
+	0x69, 0x12, 0xcc, 0x10, 0x82, 0x92, 0xeb, 0x79, 0x91, 0xa5, 0xed, 0x6a, 0x7b, 0x35, 0xcc, 0xd7,
+	0xc8, 0x02, 0x83, 0x46, 0xfe, 0xd9, 0x19, 0x89, 0xac, 0x22, 0x67, 0x2b, 0x12, 0xbd, 0x0f, 0xb5,
+	0x57, 0x49, 0x30, 0x9b, 0x06, 0xee, 0x82, 0x58, 0x3a, 0x97, 0x55, 0x19, 0x63, 0xe8, 0x2e, 0x08,
+	0xba, 0x07, 0xe5, 0x24, 0x76, 0xcf, 0x88, 0x55, 0xe2, 0x02, 0x41, 0xa0, 0x4f, 0xc0, 0x8c, 0x88,
+	0x3b, 0xa3, 0x7e, 0x18, 0x4c, 0xd5, 0xa9, 0x65, 0xae, 0xb0, 0xa3, 0xf8, 0x13, 0x79, 0x7a, 0x0b,
+	0x2a, 0xe4, 0x92, 0x04, 0x34, 0xb6, 0x2a, 0xbb, 0xfa, 0xde, 0x76, 0xfb, 0x7e, 0x4b, 0xfa, 0xde,
+	0x3d, 0x77, 0xa9, 0xc3, 0x24, 0xad, 0xc9, 0xd5, 0x92, 0x60, 0xa9, 0x85, 0x5a, 0x50, 0x8b, 0x67,
+	0xe7, 0xc4, 0x4b, 0xe6, 0x24, 0xb6, 0x8c, 0x5d, 0x7d, 0xaf, 0xde, 0x36, 0xd5, 0x96, 0xb1, 0x14,
+	0xe0, 0x4c, 0x05, 0x35, 0xa1, 0x4a, 0xde, 0xb8, 0x8b, 0x25, 0x53, 0xaf, 0xee, 0xea, 0xcc, 0x79,
+	0x45, 0xa3, 0x2f, 0xa1, 0x21, 0xbd, 0x9b, 0xd2, 0xab, 0x25, 0xb1, 0x6a, 0xbb, 0xda, 0xde, 0x76,
+	0xdb, 0x52, 0xc7, 0xb1, 0x58, 0xb5, 0xa4, 0x9f, 0xdc, 0x87, 0x3a, 0xcd, 0x08, 0xf4, 0x11, 0xd4,
+	0xfd, 0xb3, 0x20, 0x8c, 0xc8, 0x74, 0xe6, 0xc6, 0xc4, 0x82, 0x5d, 0x6d, 0xaf, 0x8a, 0x41, 0xb0,
+	0xba, 0x6e, 0xcc, 0x83, 0xc0, 0xe3, 0x3f, 0x0b, 0xe7, 0xd3, 0x4b, 0x12, 0xc5, 0x7e, 0x18, 0x58,
+	0xf5, 0x5d, 0x6d, 0x6f, 0x0b, 0xef, 0x28, 0xfe, 0x0b, 0xc1, 0x46, 0x4f, 0xa0, 0x31, 0x73, 0x97,
+	0xee, 0xa9, 0x3f, 0xf7, 0xa9, 0x4f, 0x62, 0xab, 0xc1, 0x43, 0x81, 0xd2, 0x50, 0x28, 0xd9, 0x15,
+	0x5e, 0xd1, 0xb3, 0x9f, 0x41, 0x3d, 0xe7, 0x1f, 0xaa, 0x41, 0x19, 0x3b, 0x87, 0xce, 0xd7, 0x66,
+	0x01, 0xd5, 0xc1, 0xe8, 0x8e, 0x06, 0x83, 0xce, 0xb0, 0x67, 0x6a, 0x08, 0xa0, 0x72, 0x8c, 0x9d,
+	0xa7, 0xfd, 0xaf, 0xcd, 0x22, 0x13, 0x3c, 0x77, 0xbe, 0x79, 0x39, 0xc2, 0x3d, 0x53, 0x47, 0x55,
+	0x28, 0x1d, 0x1e, 0x8d, 0x0e, 0xcc, 0x92, 0xfd, 0x3b, 0x0d, 0xaa, 0x2a, 0x7c, 0x0c, 0x1f, 0xb3,
+	0x28, 0x0c, 0x14, 0x3e, 0xd8, 0x9a, 0xc5, 0x91, 0xfa, 0x0b, 0xf2, 0xeb, 0x30, 0x20, 0x12, 0x20,
+	0x29, 0xcd, 0xb0, 0x33, 0x3b, 0x77, 0x83, 0x80, 0xcc, 0x25, 0x3e, 0x14, 0x89, 0xee, 0x43, 0x25,
+	0x0e, 0x93, 0x68, 0xa6, 0xf0, 0x21, 0x29, 0x66, 0xe1, 0x34, 0xf4, 0xae, 0x24, 0x28, 0xf8, 0xda,
+	0xfe, 0x87, 0x06, 0xc0, 0x42, 0x3e, 0xa6, 0x2e, 0x4d, 0x62, 0xf4, 0x10, 0x2a, 0x31, 0x5f, 0x71,
+	0x37, 0xb6, 0xdb, 0xef, 0xe5, 0xaf, 0x45, 0xe8, 0xb4, 0xc4, 0x0f, 0x96, 0x8a, 0x0c, 0x8c, 0x34,
+	0xbc, 0x20, 0x81, 0x74, 0x50, 0x10, 0xcc, 0xf3, 0xd7, 0x6e, 0x14, 0xf8, 0xc1, 0x59, 0x6c, 0xe9,
+	0x02, 0x01, 0x8a, 0xde, 0x78, 0x47, 0xa5, 0x8d, 0x77, 0x64, 0xbf, 0x0f, 0x15, 0xe9, 0x59, 0x0d,
+	0xca, 0x0e, 0xc6, 0x23, 0x6c, 0x16, 0x50, 0x05, 0x8a, 0xa3, 0xe7, 0xa6, 0x66, 0xff, 0x51, 0x03,
+	0xe3, 0x20, 0xa4, 0xfd, 0xe0, 0x55, 0xc8, 0xfe, 0x1b, 0x4f, 0x15, 0x19, 0x3d, 0xb6, 0x66, 0x11,
+	0x52, 0xc7, 0xcb, 0xec, 0x92, 0x24, 0xf3, 0x39, 0x7c, 0x1d, 0x90, 0x48, 0x46, 0x4e, 0x10, 0x68,
+	0x17, 0xea, 0x1e, 0x89, 0x67, 0x91, 0xbf, 0xa4, 0xca, 0xa5, 0x1a, 0xce, 0xb3, 0x90, 0x0d, 0x65,
+	0x96, 0x84, 0xb1, 0x55, 0xe6, 0x39, 0xd0, 0xc8, 0x47, 0x07, 0x0b, 0x91, 0xfd, 0x4f, 0x0d, 0xb6,
+	0xbb, 0x61, 0x10, 0x90, 0x19, 0x1d, 0x90, 0x98, 0x67, 0xe6, 0xff, 0x83, 0x7e, 0x1a, 0x52, 0xee,
+	0x5b, 0xbd, 0xbd, 0xa3, 0x36, 0x49, 0xd7, 0x31, 0x93, 0xa1, 0x4f, 0xd2, 0xc0, 0x17, 0xb9, 0xd6,
+	0x9d, 0x9c, 0xd6, 0x5a, 0xc0, 0xdf, 0x05, 0x63, 0xe6, 0xce, 0xe7, 0x53, 0xdf, 0xe3, 0xee, 0x97,
+	0x70, 0x85, 0x91, 0x7d, 0x0f, 0x7d, 0x06, 0xc6, 0x42, 0x58, 0xe4, 0xbe, 0xd7, 0xdb, 0x77, 0xf3,
+	0x69, 0x2d, 0x9d, 0xc1, 0x4a, 0x07, 0x99, 0xa0, 0x93, 0xc0, 0xe3, 0x68, 0xa8, 0x62, 0xb6, 0x64,
+	0x61, 0x21, 0x51, 0x14, 0x46, 0x56, 0x45, 0x84, 0x85, 0x13, 0xf6, 0x4b, 0xa8, 0x62, 0x72, 0xe6,
+	0xc7, 0x34, 0xba, 0x62, 0xd7, 0xea, 0x07, 0x31, 0x75, 0x83, 0x99, 0x0a, 0x75, 0x4a, 0xa3, 0xcf,
+	0xc1, 0x20, 0x01, 0x8d, 0x58, 0x2a, 0x15, 0x79, 0x78, 0xde, 0x51, 0xe6, 0xd5, 0x76, 0x27, 0xa0,
+	0xd1, 0x15, 0x56, 0x5a, 0xf6, 0x5f, 0x35, 0xd8, 0x5a, 0x11, 0x31, 0x97, 0x2e, 0xc8, 0x95, 0x3c,
+	0x99, 0x2d, 0xd7, 0xef, 0x50, 0xcf, 0xee, 0x30, 0xef, 0x8a, 0xbe, 0xe6, 0x8a, 0x0c, 0x78, 0xe9,
+	0x7b, 0x02, 0x9e, 0xc2, 0xb6, 0x9c, 0x87, 0xad, 0x05, 0x46, 0x44, 0x16, 0xe1, 0x25, 0xf1, 0x78,
+	0x0c, 0xaa, 0x58, 0x91, 0xf6, 0x0b, 0x68, 0xc8, 0x08, 0x76, 0xe7, 0xae, 0xbf, 0xd8, 0xe0, 0x6a,
+	0xde, 0xa1, 0xe2, 0x9a, 0x43, 0x16, 0x18, 0x67, 0x91, 0x1b, 0x50, 0x22, 0xee, 0xac, 0x8a, 0x15,
+	0x69, 0xff, 0x06, 0xa0, 0x93, 0x78, 0x3e, 0xfd, 0x45, 0x42, 0xa2, 0xab, 0xcc, 0x2b, 0x2d, 0xef,
+	0x15, 0x82, 0x52, 0x12, 0xa7, 0x3d, 0x82, 0xaf, 0x7f, 0xb0, 0x41, 0xc4, 0xd4, 0x8d, 0x44, 0x04,
+	0x74, 0x2c, 0x88, 0xfc, 0x85, 0xeb, 0xfc, 0xc2, 0xed, 0x7f, 0x6b, 0x50, 0xe7, 0xd6, 0x31, 0x99,
+	0x85, 0x91, 0xc7, 0x0c, 0xb1, 0xfa, 0xc2, 0xad, 0xeb, 0x98, 0xaf, 0xd1, 0x87, 0x00, 0x12, 0x31,
+	0x0c, 0x71, 0xc2, 0x85, 0x9a, 0xe4, 0xf4, 0xbd, 0x5c, 0xb1, 0xd1, 0xd7, 0x8b, 0x0d, 0xf7, 0xb9,
+	0x94, 0xf3, 0x39, 0x57, 0xb2, 0xca, 0xab, 0x25, 0xeb, 0x9e, 0x4a, 0xac, 0x0a, 0xaf, 0x15, 0x82,
+	0x60, 0xfa, 0x61, 0x42, 0x67, 0xe1, 0x82, 0x58, 0x86, 0xd0, 0x97, 0x24, 0xeb, 0x03, 0x5e, 0x12,
+	0xb9, 0xbc, 0xd7, 0x2d, 0x58, 0x8f, 0x61, 0xfe, 0x82, 0x62, 0x0d, 0xe2, 0x0c, 0xca, 0xb5, 0x3c,
+	0x94, 0x7f, 0xaf, 0xc1, 0xce, 0x24, 0x72, 0x03, 0x91, 0xd1, 0x02, 0x73, 0x9b, 0xfe, 0x73, 0x2e,
+	0x93, 0x8a, 0xb7, 0xc8, 0xa4, 0x87, 0x50, 0x8b, 0x48, 0xbc, 0x0c, 0x83, 0x98, 0x88, 0x6a, 0x77,
+	0xc3, 0x86, 0x4c, 0xcb, 0xfe, 0x0a, 0x76, 0x7a, 0xbc, 0xb0, 0x9c, 0x12, 0x4c, 0x7e, 0x95, 0x90,
+	0x98, 0x6e, 0x2c, 0x8b, 0xda, 0xe6, 0xb2, 0x78, 0x0e, 0xb5, 0xb4, 0x2e, 0xfc, 0x37, 0x35, 0x7b,
+	0x4f, 0x85, 0x5b, 0x24, 0x2a, 0xba, 0xbe, 0x43, 0x55, 0xb3, 0xbf, 0x95, 0xa1, 0x9e, 0xfb, 0x0b,
+	0x69, 0x0f, 0xd1, 0xb2, 0x1e, 0xb2, 0x11, 0x9e, 0x37, 0x77, 0xa7, 0x15, 0xe0, 0x96, 0xd6, 0x80,
+	0xfb, 0x18, 0xea, 0x2e, 0xa5, 0xee, 0xec, 0x7c, 0xc1, 0xa7, 0x93, 0xf2, 0xaa, 0x7b, 0x9d, 0x54,
+	0x84, 0xf3, 0x6a, 0xec, 0x48, 0x7a, 0x1e, 0x11, 0xd7, 0x9b, 0xfa, 0x22, 0x6f, 0x59, 0x9f, 0xe4,
+	0x8c, 0xbe, 0xb7, 0x86, 0x5f, 0x63, 0x1d, 0xbf, 0x07, 0xd0, 0x90, 0x7b, 0x23, 0xb2, 0x9c, 0x5f,
+	0x71, 0x28, 0x6d, 0xb7, 0x3f, 0xda, 0x70, 0x7d, 0xad, 0x09, 0xd7, 0xc3, 0x4c, 0x0d, 0xd7, 0x69,
+	0x46, 0xa0, 0x9f, 0x02, 0x5c, 0xfa, 0xb1, 0x2f, 0xa6, 0x05, 0x39, 0xd0, 0xfc, 0xdf, 0xa6, 0x13,
+	0x5e, 0xa4, 0x5a, 0x38, 0xb7, 0x03, 0x3d, 0x80, 0xaa, 0x9a, 0xd0, 0xf8, 0x48, 0x93, 0x9b, 0xae,
+	0xb0, 0xe4, 0xe3, 0x54, 0x03, 0xfd, 0x18, 0xca, 0x7c, 0x2c, 0xb3, 0xea, 0xab, 0x9d, 0x22, 0x9d,
+	0xdd, 0xb0, 0x90, 0xe7, 0x52, 0xb3, 0xb1, 0x92, 0x9a, 0x9f, 0x80, 0x11, 0x93, 0x98, 0xe3, 0x6b,
+	0x6b, 0xb5, 0x42, 0x8e, 0x05, 0x1b, 0x2b, 0x39, 0x4b, 0x23, 0x3f, 0x58, 0x26, 0xd4, 0xda, 0x16,
+	0x69, 0xc4, 0x09, 0xf4, 0x01, 0xd4, 0xd8, 0xf8, 0x4a, 0xe2, 0x98, 0x78, 0xd6, 0x0e, 0xaf, 0x67,
+	0x19, 0x83, 0x99, 0x4d, 0x96, 0x9e, 0x4b, 0x89, 0x65, 0x72, 0x91, 0xa4, 0xec, 0x7d, 0xa8, 0xe7,
+	0x22, 0xc8, 0xc6, 0xa0, 0x71, 0x67, 0xe0, 0x98, 0x05, 0x36, 0x29, 0x4d, 0x9e, 0x61, 0xa7, 0xc3,
+	0xa6, 0x26, 0x36, 0x42, 0x3d, 0xeb, 0x0c, 0x87, 0xce, 0x91, 0x59, 0xb4, 0x1f, 0x01, 0x64, 0x11,
+	0xe3, 0x03, 0xd5, 0xc9, 0xc1, 0x51, 0xbf, 0x6b, 0x16, 0xd0, 0x16, 0xd4, 0x9c, 0xe3, 0x67, 0xce,
+	0xc0, 0xc1, 0x9d, 0x23, 0x31, 0x6b, 0xf5, 0xfa, 0xd8, 0xe9, 0x4e, 0xcc, 0xa2, 0x4d, 0xc1, 0x90,
+	0x7f, 0x83, 0x8d, 0xb9, 0x32, 0xaa, 0x22, 0x33, 0xee, 0xaf, 0xfd, 0xcf, 0x56, 0x47, 0xc4, 0x56,
+	0x6a, 0xf1, 0x71, 0xdc, 0x5f, 0x90, 0x30, 0xa1, 0x1c, 0xcb, 0x65, 0xac, 0x48, 0xfb, 0x23, 0xa8,
+	0x08, 0x5d, 0xd4, 0x80, 0x6a, 0x77, 0x34, 0x9c, 0xf4, 0x87, 0x27, 0xcc, 0x75, 0x03, 0x74, 0x87,
+	0x4d, 0x7b, 0x76, 0xc0, 0x9a, 0xa4, 0x3c, 0xe6, 0xf3, 0x35, 0xb3, 0xef, 0xae, 0x5f, 0xe6, 0xba,
+	0x5d, 0x35, 0xbc, 0x14, 0xb3, 0xe1, 0xc5, 0xfe, 0x30, 0xb5, 0x68, 0x80, 0xde, 0xe9, 0xf5, 0x44,
+	0x9c, 0xb0, 0x33, 0x18, 0xbd, 0x70, 0x4c, 0xcd, 0xfe, 0xae, 0x08, 0x90, 0xa5, 0x03, 0xef, 0x1b,
+	0x3e, 0x9d, 0x93, 0xb4, 0x6f, 0x30, 0x82, 0x41, 0x9f, 0x2f, 0xa6, 0x73, 0x3f, 0xb8, 0x50, 0xa5,
+	0x9b, 0x73, 0x8e, 0xfc, 0xe0, 0x82, 0x99, 0xa5, 0xe4, 0x0d, 0x95, 0x09, 0xca, 0xd7, 0xec, 0xa0,
+	0x59, 0x38, 0x0f, 0x55, 0xdd, 0x16, 0x04, 0xda, 0x87, 0xca, 0x2b, 0x9f, 0xcc, 0x3d, 0x95, 0x91,
+	0xd6, 0xf5, 0x8c, 0x6c, 0x3d, 0x65, 0x0a, 0x58, 0xea, 0xb1, 0x94, 0xf4, 0x17, 0x2c, 0xe7, 0x92,
+	0x68, 0xae, 0x52, 0x92, 0x33, 0x4e, 0xa2, 0xb9, 0xc8, 0xd7, 0x64, 0x71, 0xca, 0x85, 0x86, 0xca,
+	0xd7, 0x64, 0x71, 0xca, 0x84, 0x6c, 0x0e, 0x0e, 0x3d, 0x62, 0x55, 0xe5, 0x1c, 0x1c, 0x7a, 0x84,
+	0xb5, 0xd6, 0x85, 0x1b, 0x5d, 0x78, 0xe1, 0xeb, 0x80, 0xa7, 0x57, 0x15, 0xa7, 0x34, 0x83, 0xdb,
+	0xab, 0x30, 0xa4, 0x24, 0xe2, 0xa9, 0x53, 0xc3, 0x92, 0x6a, 0xf6, 0xa1, 0xcc, 0x5d, 0xba, 0x21,
+	0x36, 0xf7, 0xa0, 0x7c, 0xe9, 0xce, 0x13, 0x15, 0x74, 0x41, 0x30, 0x6e, 0x7c, 0x1e, 0x46, 0x54,
+	0x76, 0x69, 0x41, 0xd8, 0x18, 0xea, 0xc7, 0x61, 0x9c, 0xd6, 0xc0, 0xcd, 0x4d, 0xfa, 0xed, 0x7a,
+	0x86, 0xfd, 0x77, 0x0d, 0xea, 0xfd, 0x80, 0x92, 0x33, 0xd1, 0xb2, 0x36, 0x0e, 0xb0, 0x1f, 0x40,
+	0xed, 0x75, 0x18, 0x5d, 0xc4, 0x4b, 0x37, 0x1d, 0x29, 0x32, 0xc6, 0xb5, 0xf7, 0x8b, 0x7e, 0xbb,
+	0xf7, 0x0b, 0xda, 0x86, 0xa2, 0xef, 0xc9, 0xfb, 0x2d, 0xfa, 0xde, 0xc6, 0xbe, 0x53, 0xde, 0xdc,
+	0x77, 0xfe, 0xa5, 0xc1, 0x9d, 0x81, 0xef, 0x79, 0x73, 0xf2, 0xda, 0x8d, 0x88, 0x8a, 0x47, 0x07,
+	0x6a, 0x9e, 0x1f, 0x91, 0x3c, 0xe4, 0x3f, 0x56, 0x5e, 0x5c, 0xd3, 0x6e, 0xf5, 0x94, 0x2a, 0xce,
+	0x76, 0xbd, 0x6d, 0xc3, 0xfd, 0x8c, 0x8d, 0x69, 0xbc, 0x6b, 0x5a, 0xfa, 0xf7, 0xa8, 0x4b, 0x1d,
+	0xfb, 0x47, 0x50, 0x4b, 0xad, 0xb2, 0x0a, 0xd3, 0x1f, 0x1e, 0x8c, 0x4e, 0x86, 0x2c, 0xa5, 0x1a,
+	0x50, 0x1d, 0x9d, 0x4c, 0x04, 0xa5, 0xd9, 0x8f, 0x01, 0xc6, 0x34, 0x8c, 0xdc, 0x33, 0xf2, 0x9c,
+	0xdc, 0x34, 0x8b, 0xc9, 0xb9, 0xaf, 0x98, 0xce, 0x7d, 0xf6, 0x2f, 0xa1, 0x2e, 0x77, 0xf5, 0x29,
+	0x59, 0xdc, 0x76, 0x5b, 0x06, 0x40, 0xf6, 0x0f, 0x1a, 0x0a, 0x80, 0x26, 0xe8, 0x94, 0xce, 0xe5,
+	0xdc, 0xc6, 0x96, 0xf6, 0x29, 0x98, 0xea, 0xf8, 0x60, 0x16, 0x91, 0x34, 0xdd, 0x6f, 0x69, 0xc3,
+	0x23, 0x73, 0xea, 0x72, 0x1b, 0x3a, 0x16, 0xc4, 0x06, 0x1b, 0x7f, 0x28, 0x42, 0x2d, 0x6d, 0x1f,
+	0xe8, 0x53, 0x28, 0xf1, 0x97, 0xf9, 0x5a, 0xd1, 0x5c, 0xfb, 0x36, 0xc0, 0x75, 0xd2, 0x1a, 0x52,
+	0xcc, 0xd5, 0x90, 0x8f, 0x61, 0x6b, 0x19, 0x91, 0x4b, 0x3f, 0x4c, 0xe2, 0x69, 0xae, 0xc0, 0x34,
+	0x14, 0x73, 0x42, 0xde, 0x50, 0xfb, 0xcf, 0x1a, 0x94, 0xd8, 0x39, 0xec, 0x3e, 0x4e, 0x86, 0xcf,
+	0x87, 0xa3, 0x97, 0x43, 0xb3, 0x80, 0xee, 0xc0, 0xd6, 0xc0, 0x19, 0x1c, 0x38, 0x78, 0xfa, 0xf3,
+	0x51, 0x7f, 0xe8, 0xb0, 0x8e, 0xb0, 0x03, 0x75, 0xc9, 0x3a, 0x72, 0x9e, 0x4e, 0xcc, 0x22, 0xba,
+	0x0b, 0x3b, 0xb2, 0x45, 0x4c, 0xbb, 0xd8, 0xe9, 0x4c, 0x1c, 0xf6, 0xa8, 0xbe, 0x03, 0x5b, 0x93,
+	0xd1, 0x71, 0xbf, 0x3b, 0x65, 0xa2, 0x43, 0xa7, 0x67, 0x96, 0xd0, 0x3d, 0x30, 0x8f, 0xb1, 0x33,
+	0x76, 0x86, 0x5d, 0x27, 0xe5, 0x96, 0x11, 0x82, 0xed, 0x81, 0x33, 0x1e, 0x77, 0x0e, 0x9d, 0xa9,
+	0xd3, 0xeb, 0xb3, 0xcd, 0x15, 0x76, 0xa2, 0xe2, 0xf5, 0x9c, 0x23, 0x87, 0x31, 0x8d, 0x4f, 0xbf,
+	0xd5, 0x00, 0xb2, 0x1c, 0x42, 0xf7, 0x01, 0x49, 0x37, 0xa7, 0xdd, 0xce, 0x71, 0xe7, 0xa0, 0x7f,
+	0xd4, 0x9f, 0x7c, 0x63, 0x16, 0x90, 0x09, 0x0d, 0xdc, 0xef, 0x3e, 0x9b, 0xb2, 0xa6, 0xe0, 0x0c,
+	0x27, 0xa2, 0x85, 0x89, 0x76, 0x36, 0x36, 0x8b, 0xab, 0x8d, 0x4a, 0x67, 0x96, 0x44, 0xa3, 0x9a,
+	0x4a, 0x83, 0x63, 0xb3, 0xc4, 0x74, 0xb0, 0xd3, 0xe9, 0x4e, 0xfa, 0xa3, 0xe1, 0xd8, 0x2c, 0xb3,
+	0x32, 0xef, 0xbc, 0x70, 0x86, 0x93, 0xb1, 0x59, 0x41, 0xef, 0xc0, 0x9d, 0xf1, 0x04, 0x3b, 0x9d,
+	0x41, 0x7f, 0x78, 0x38, 0x3d, 0x39, 0xee, 0x75, 0x26, 0xce, 0xd8, 0x34, 0xda, 0xdf, 0xe9, 0xa0,
+	0x1f, 0xf0, 0x57, 0xa3, 0xde, 0xf1, 0x3c, 0xb4, 0xf2, 0x0e, 0x6d, 0x6e, 0x98, 0xe6, 0xec, 0x02,
+	0x7a, 0x00, 0x15, 0xcc, 0x9f, 0x32, 0xb7, 0xd2, 0x7e, 0x04, 0x75, 0xf1, 0x32, 0x23, 0x11, 0xb3,
+	0xb3, 0xfe, 0x84, 0x6a, 0x5e, 0x7f, 0x9e, 0xda, 0x05, 0x36, 0x06, 0x0b, 0x13, 0xb7, 0xdf, 0xf2,
+	0x33, 0x30, 0xe4, 0x5b, 0x19, 0x65, 0x38, 0x5b, 0x79, 0x3c, 0x37, 0x6f, 0xe0, 0xdb, 0x85, 0x3d,
+	0x6d, 0x5f, 0x43, 0x5f, 0x42, 0x7d, 0x4c, 0x02, 0x4f, 0x32, 0xd1, 0xa6, 0x3a, 0xd0, 0xdc, 0xc4,
+	0xb4, 0x0b, 0xfb, 0x1a, 0x7a, 0x08, 0x25, 0x56, 0xd7, 0xb3, 0x5d, 0xb9, 0x2a, 0x7f, 0x43, 0x60,
+	0x9e, 0xa8, 0xc7, 0x30, 0x89, 0xb2, 0x6d, 0xb9, 0x3a, 0xde, 0xdc, 0xc4, 0xb4, 0x0b, 0xe8, 0x0b,
+	0xa8, 0x8d, 0x93, 0x53, 0x31, 0xf0, 0xff, 0xc0, 0xc6, 0x35, 0x2f, 0xdb, 0xbf, 0x85, 0xea, 0x41,
+	0x48, 0x9f, 0xf2, 0x17, 0xd1, 0xff, 0xf4, 0x77, 0x9f, 0x40, 0x55, 0xbd, 0x39, 0x50, 0x3a, 0x93,
+	0xac, 0xbd, 0x42, 0x9a, 0xeb, 0xf7, 0x66, 0x17, 0xda, 0x7f, 0x2a, 0x82, 0x21, 0x4b, 0x10, 0xda,
+	0x07, 0xfd, 0x90, 0x50, 0x94, 0x06, 0x27, 0xab, 0x97, 0xcd, 0xbb, 0x6b, 0x3c, 0x56, 0x0d, 0x39,
+	0x2a, 0xf4, 0x31, 0xa1, 0x68, 0x93, 0xf4, 0xa6, 0x2d, 0x8f, 0xa0, 0xd2, 0x23, 0x73, 0x42, 0xc9,
+	0xdb, 0xd8, 0x79, 0x04, 0xa5, 0x23, 0x3f, 0x7e, 0x1b, 0xd7, 0xf6, 0x35, 0xf4, 0x15, 0xd4, 0xb2,
+	0xaa, 0x6a, 0xad, 0x6b, 0x29, 0xc9, 0x0d, 0xfb, 0xdb, 0x7f, 0xd1, 0xc0, 0xe8, 0xce, 0x13, 0x0e,
+	0x86, 0x07, 0x50, 0x1a, 0x5f, 0x05, 0x33, 0x64, 0xae, 0x7f, 0xf4, 0x68, 0x5e, 0xe3, 0xd8, 0x05,
+	0xf4, 0x13, 0x28, 0x8b, 0xcf, 0x08, 0xf7, 0xd2, 0x46, 0x99, 0xfb, 0xb8, 0xd0, 0xdc, 0xc8, 0xb5,
+	0x0b, 0xe8, 0x31, 0x18, 0x3d, 0x32, 0xf7, 0x2f, 0xf3, 0xe0, 0xcb, 0x5f, 0xfd, 0x46, 0xcc, 0xb6,
+	0x0f, 0xa0, 0xdc, 0xf1, 0x16, 0x7e, 0x80, 0xbe, 0x00, 0xe0, 0x9f, 0x19, 0xf8, 0x93, 0x3f, 0x0b,
+	0x54, 0xf6, 0xfd, 0xa1, 0x79, 0x77, 0x85, 0x27, 0xbe, 0x0a, 0x70, 0x10, 0x8e, 0x00, 0xb2, 0x56,
+	0x8e, 0x3a, 0x60, 0x1c, 0x47, 0xe1, 0x8c, 0xc4, 0x31, 0x7a, 0xef, 0xc6, 0x4e, 0xdf, 0xbc, 0x59,
+	0x64, 0x17, 0x4e, 0x2b, 0x7c, 0xb6, 0x78, 0xf4, 0x9f, 0x01, 0x00, 0xe6, 0x29, 0x0f, 0x31, 0x36,
+	0x17, 0x00, 0x00,
 }
@@ -0,0 +1,99 @@
+package botrpc
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// Handler handles a message a func was triggered by and sends the responses
+// with send.
+type Handler func(in *ChatMessage, send func(*ChatMessage) error) error
+
+// ServeConnect registers bot with the router over Connect and calls handle
+// with the messages the router sends until the stream closes. Bots using it
+// don't need to listen on a port or know their own address.
+func ServeConnect(ctx context.Context, c BotClient, bot *BotInfo, handle Handler) error {
+	conn, err := ConnectBot(ctx, c, bot)
+	if err != nil {
+		return err
+	}
+	return conn.Serve(handle)
+}
+
+// BotConn is a bot registered with the router over Connect.
+type BotConn struct {
+	// Status is the reply of the router to the registration, with the
+	// tokens of the funcs.
+	Status *BotStatus
+
+	stream Bot_ConnectClient
+	mu     sync.Mutex
+}
+
+// ConnectBot registers bot with the router over Connect. The funcs of bot
+// are routed to it once ConnectBot returns, but their messages are only
+// handled after calling Serve.
+func ConnectBot(ctx context.Context, c BotClient, bot *BotInfo) (*BotConn, error) {
+	stream, err := c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&ConnectMessage{Bot: bot}); err != nil {
+		return nil, err
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if first.Status == nil {
+		return nil, fmt.Errorf("registering %v failed", bot.Name)
+	}
+	if err := first.Status.Err(); err != nil {
+		return nil, fmt.Errorf("registering %v failed: %v", bot.Name, err)
+	}
+	return &BotConn{Status: first.Status, stream: stream}, nil
+}
+
+func (b *BotConn) send(m *ConnectMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stream.Send(m)
+}
+
+// Serve calls handle with the messages the router sends until the stream
+// closes.
+func (b *BotConn) Serve(handle Handler) error {
+	for {
+		m, err := b.stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if m.Message == nil {
+			continue
+		}
+		go func(m *ConnectMessage) {
+			err := handle(m.Message, func(out *ChatMessage) error {
+				return b.send(&ConnectMessage{CallId: m.CallId, Message: out})
+			})
+			end := &ConnectMessage{CallId: m.CallId, End: true}
+			if err != nil {
+				end.Error = err.Error()
+			}
+			b.send(end)
+		}(m)
+	}
+}
+
+// Close closes the stream, which removes the funcs of the bot from the
+// router.
+func (b *BotConn) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stream.CloseSend()
+}
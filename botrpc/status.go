@@ -0,0 +1,30 @@
+package botrpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Err returns an error with the warnings of a func the router rejected, or
+// nil if it was added.
+func (st *FuncStatus) Err() error {
+	if st.Status == FuncStatus_OK {
+		return nil
+	}
+	return fmt.Errorf("func rejected: %v", strings.Join(st.Warnings, "; "))
+}
+
+// Err returns an error with the warnings of the funcs the router rejected, or
+// nil if the bot was registered.
+func (st *BotStatus) Err() error {
+	if st.Status == FuncStatus_OK {
+		return nil
+	}
+	var w []string
+	for _, f := range st.Funcs {
+		if f.Status != FuncStatus_OK {
+			w = append(w, f.Warnings...)
+		}
+	}
+	return fmt.Errorf("bot rejected: %v", strings.Join(w, "; "))
+}
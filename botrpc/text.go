@@ -0,0 +1,50 @@
+package botrpc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PlainText renders the message body and all of its attachments as plain
+// text. Integrations use it when the platform can't display rich content.
+func (m *ChatMessage) PlainText() string {
+	var buf bytes.Buffer
+	buf.WriteString(m.Body)
+	for _, a := range m.Attachments {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(a.PlainText())
+	}
+	return buf.String()
+}
+
+// PlainText renders the attachment as plain text.
+func (a *Attachment) PlainText() string {
+	var lines []string
+	switch {
+	case a.Title != "" && a.TitleLink != "":
+		lines = append(lines, fmt.Sprintf("%s (%s)", a.Title, a.TitleLink))
+	case a.Title != "":
+		lines = append(lines, a.Title)
+	}
+	if a.Text != "" {
+		lines = append(lines, a.Text)
+	}
+	for _, f := range a.Fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", f.Title, f.Value))
+	}
+	if a.Code != "" {
+		for _, l := range strings.Split(strings.TrimRight(a.Code, "\n"), "\n") {
+			lines = append(lines, "    "+l)
+		}
+	}
+	if a.ImageUrl != "" {
+		lines = append(lines, a.ImageUrl)
+	}
+	if a.Footer != "" {
+		lines = append(lines, a.Footer)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,13 @@
+package botrpc
+
+// ProtocolVersion is the version of the protocol in this package. Bots and
+// integrations send it in Func and Integration so the router knows which
+// messages they understand. It is increased whenever a change needs the
+// router to treat older peers differently.
+//
+// Version 1 is the protocol before versioning. Peers that don't send a version
+// are assumed to speak it.
+const ProtocolVersion = 2
+
+// MinProtocolVersion is the oldest version the router still talks to.
+const MinProtocolVersion = 1
@@ -0,0 +1,57 @@
+// Package hello implements the funcs of hellobot so they can be served by the
+// hellobot command and tested in process.
+package hello
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// Server implements botrpc.BotFuncsServer for hellobot.
+type Server struct{}
+
+func (s *Server) SendMessage(in *botrpc.ChatMessage, stream botrpc.BotFuncs_SendMessageServer) error {
+	return Handle(in, stream.Send)
+}
+
+// Describe returns the manifest so the router can find hellobot without it
+// registering.
+func (s *Server) Describe(ctx context.Context, in *botrpc.DescribeRequest) (*botrpc.BotInfo, error) {
+	return Manifest(), nil
+}
+
+// Handle calls the func in is for and sends its responses with send.
+func Handle(in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) error {
+	switch in.FuncName {
+	case "hello":
+		hello(in, send)
+	default:
+		return fmt.Errorf("func does not exist: %v", *in)
+	}
+	return nil
+}
+
+// Manifest describes hellobot and its funcs.
+func Manifest() *botrpc.BotInfo {
+	return &botrpc.BotInfo{
+		Name:        "hellobot",
+		Version:     "1.0",
+		Description: "says hello back.",
+		Funcs: []*botrpc.Func{{
+			Trigger:         "hello",
+			TriggerType:     botrpc.Func_KEYWORD,
+			IgnoreCase:      true,
+			FuncName:        "hello",
+			Usage:           "bot responds when you say \"hello\".",
+			ProtocolVersion: botrpc.ProtocolVersion,
+		}},
+	}
+}
+
+func hello(in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) {
+	in.Body = "hey there"
+	send(in)
+}
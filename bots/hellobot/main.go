@@ -10,24 +10,21 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/foolusion/chatbot/botrpc"
+	"github.com/foolusion/chatbot/bots/hellobot/hello"
 )
 
 const address = "localhost:8173"
 const port = ":8081"
 
-type server struct{}
-
-func (s *server) SendMessage(in *botrpc.ChatMessage, stream botrpc.BotFuncs_SendMessageServer) error {
-	switch in.FuncName {
-	case "hello":
-		hello(in, stream)
-	default:
-		return fmt.Errorf("func does not exist: %v", *in)
-	}
-	return nil
-}
-
 func main() {
+	// with HELLOBOT_CONNECT set hellobot connects to the router instead of
+	// listening.
+	if os.Getenv("HELLOBOT_CONNECT") != "" {
+		if err := connect(); err != nil {
+			fmt.Fprintf(os.Stdout, "error connecting hellobot: %v", err)
+		}
+		return
+	}
 	if err := register(); err != nil {
 		fmt.Fprintf(os.Stdout, "error registering hellobot: %v", err)
 	}
@@ -37,7 +34,7 @@ func main() {
 		fmt.Fprintf(os.Stdout, "failed to listen: %v\n", err)
 	}
 	s := grpc.NewServer()
-	botrpc.RegisterBotFuncsServer(s, &server{})
+	botrpc.RegisterBotFuncsServer(s, &hello.Server{})
 	s.Serve(lis)
 }
 
@@ -51,24 +48,25 @@ func register() error {
 		fmt.Fprintf(os.Stdout, "error connecting with client: %v", err)
 	}
 	defer conn.Close()
-	cfd := &botrpc.Func{
-		Addr:     addr + port,
-		Trigger:  "hello",
-		FuncName: "hello",
-		Usage:    "bot responds when you say \"hello\".",
+	bot := hello.Manifest()
+	for _, f := range bot.Funcs {
+		f.Addr = addr + port
 	}
 	c := botrpc.NewBotClient(conn)
-	// eventually do something with fs
-	_, err = c.Add(context.Background(), cfd)
+	st, err := c.RegisterBot(context.Background(), bot)
 	if err != nil {
 		return err
 	}
-	return nil
+	return st.Err()
 }
 
-func hello(in *botrpc.ChatMessage, stream botrpc.BotFuncs_SendMessageServer) {
-	in.Body = "hey there"
-	stream.Send(in)
+func connect() error {
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return botrpc.ServeConnect(context.Background(), botrpc.NewBotClient(conn), hello.Manifest(), hello.Handle)
 }
 
 func getIP() (string, error) {
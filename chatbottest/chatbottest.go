@@ -0,0 +1,210 @@
+// Package chatbottest runs a chatbot router in process for testing bots and
+// integrations. The router listens on an in-memory connection, so tests
+// neither need a free port nor a router running somewhere else.
+//
+// A test adds the bots it needs, real ones with AddBotFuncs or fakes with
+// AddFunc, and sends messages the way an integration would:
+//
+//	r, err := chatbottest.NewRouter()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer r.Close()
+//	if _, err := r.AddBotFuncs(hello.Manifest(), &hello.Server{}); err != nil {
+//		t.Fatal(err)
+//	}
+//	r.Expect(t, &botrpc.ChatMessage{Body: "hello"}, "hey there")
+//
+// The router keeps its state in globals, so only one Router can run at a time
+// and tests using it must not run in parallel.
+package chatbottest
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/foolusion/chatbot/botrpc"
+	"github.com/foolusion/chatbot/router"
+)
+
+// bufSize is the buffer size of the in-memory connections.
+const bufSize = 1 << 20
+
+// Router is a router running in process.
+type Router struct {
+	// Client is a client of the Bot service of the router, for the rpcs
+	// the helpers don't cover, like Register, Subscribe and Post.
+	Client botrpc.BotClient
+	// Storage is a client of the Storage service of the router.
+	Storage botrpc.StorageClient
+
+	lis  *bufconn.Listener
+	srv  *grpc.Server
+	conn *grpc.ClientConn
+	dir  string
+
+	mu   sync.Mutex
+	bots []*botrpc.BotConn
+}
+
+// NewRouter starts a router with the default configuration, empty storage
+// and no bots. The audit log and transcript are off.
+func NewRouter() (*Router, error) {
+	dir, err := ioutil.TempDir("", "chatbottest")
+	if err != nil {
+		return nil, err
+	}
+	if err := router.Reset(filepath.Join(dir, "chatbot-storage.json")); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("resetting router: %v", err)
+	}
+
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	router.RegisterServers(s)
+	go s.Serve(lis)
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	if err != nil {
+		s.Stop()
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("dialing router: %v", err)
+	}
+	return &Router{
+		Client:  botrpc.NewBotClient(conn),
+		Storage: botrpc.NewStorageClient(conn),
+		lis:     lis,
+		srv:     s,
+		conn:    conn,
+		dir:     dir,
+	}, nil
+}
+
+// Close disconnects the bots, stops the router and removes its storage.
+func (r *Router) Close() error {
+	r.mu.Lock()
+	for _, b := range r.bots {
+		b.Close()
+	}
+	r.bots = nil
+	r.mu.Unlock()
+	r.conn.Close()
+	r.srv.Stop()
+	return os.RemoveAll(r.dir)
+}
+
+// AddBot registers bot with the router over Connect and calls handle with the
+// messages its funcs are triggered by until the router is closed. The funcs
+// don't need an address. The returned status has the tokens of the funcs.
+func (r *Router) AddBot(bot *botrpc.BotInfo, handle botrpc.Handler) (*botrpc.BotStatus, error) {
+	b, err := botrpc.ConnectBot(context.Background(), r.Client, bot)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.bots = append(r.bots, b)
+	r.mu.Unlock()
+	go b.Serve(handle)
+	return b.Status, nil
+}
+
+// AddBotFuncs registers bot with the router and handles its funcs by calling
+// funcs.SendMessage, so a real bot can be tested without listening on a
+// port.
+func (r *Router) AddBotFuncs(bot *botrpc.BotInfo, funcs botrpc.BotFuncsServer) (*botrpc.BotStatus, error) {
+	return r.AddBot(bot, Handle(funcs))
+}
+
+// AddFunc registers a fake bot called name with one func triggered by the
+// regular expression trigger and handled by handle.
+func (r *Router) AddFunc(name, trigger string, handle botrpc.Handler) (*botrpc.BotStatus, error) {
+	return r.AddBot(&botrpc.BotInfo{
+		Name: name,
+		Funcs: []*botrpc.Func{{
+			Trigger:         trigger,
+			FuncName:        name,
+			ProtocolVersion: botrpc.ProtocolVersion,
+		}},
+	}, handle)
+}
+
+// Handle returns a Handler that calls funcs.SendMessage with the message and
+// sends the responses it streams.
+func Handle(funcs botrpc.BotFuncsServer) botrpc.Handler {
+	return func(in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) error {
+		return funcs.SendMessage(in, &sendStream{ctx: context.Background(), send: send})
+	}
+}
+
+// sendStream is a BotFuncs_SendMessageServer that hands the messages to
+// send. The rest of grpc.ServerStream isn't used by bots and panics.
+type sendStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*botrpc.ChatMessage) error
+}
+
+func (s *sendStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *sendStream) Send(m *botrpc.ChatMessage) error {
+	return s.send(m)
+}
+
+// Send sends in to the router as an integration would and returns the
+// responses of the funcs it triggered.
+func (r *Router) Send(in *botrpc.ChatMessage) ([]*botrpc.ChatMessage, error) {
+	stream, err := r.Client.SendMessage(context.Background(), in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*botrpc.ChatMessage
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, m)
+	}
+}
+
+// Expect sends in to the router and reports an error on t unless the bodies
+// of the responses are want, in order.
+func (r *Router) Expect(t testing.TB, in *botrpc.ChatMessage, want ...string) {
+	out, err := r.Send(in)
+	if err != nil {
+		t.Errorf("sending %q: %v", in.Body, err)
+		return
+	}
+	if got := Bodies(out); !reflect.DeepEqual(got, want) && (len(got) != 0 || len(want) != 0) {
+		t.Errorf("responses to %q = %q, want %q", in.Body, got, want)
+	}
+}
+
+// Bodies returns the bodies of ms.
+func Bodies(ms []*botrpc.ChatMessage) []string {
+	var bs []string
+	for _, m := range ms {
+		bs = append(bs, m.Body)
+	}
+	return bs
+}
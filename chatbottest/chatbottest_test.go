@@ -0,0 +1,107 @@
+package chatbottest
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/foolusion/chatbot/botrpc"
+	"github.com/foolusion/chatbot/bots/hellobot/hello"
+)
+
+func newRouter(t *testing.T) *Router {
+	r, err := NewRouter()
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	return r
+}
+
+func message(body string) *botrpc.ChatMessage {
+	return &botrpc.ChatMessage{Body: body, User: "alice", Channel: "general", Source: "test"}
+}
+
+func TestBotFuncs(t *testing.T) {
+	r := newRouter(t)
+	defer r.Close()
+	if _, err := r.AddBotFuncs(hello.Manifest(), &hello.Server{}); err != nil {
+		t.Fatalf("AddBotFuncs: %v", err)
+	}
+	r.Expect(t, message("Hello everyone"), "hey there")
+	r.Expect(t, message("!hellobot.hello"), "hey there")
+	r.Expect(t, message("othello"))
+}
+
+func TestHandler(t *testing.T) {
+	r := newRouter(t)
+	defer r.Close()
+	st, err := r.AddBot(hello.Manifest(), hello.Handle)
+	if err != nil {
+		t.Fatalf("AddBot: %v", err)
+	}
+	if st.Status != botrpc.FuncStatus_OK || len(st.Funcs) != 1 || st.Funcs[0].Token == "" {
+		t.Fatalf("AddBot status = %v, want OK with a token", st)
+	}
+	r.Expect(t, message("hello"), "hey there")
+}
+
+func TestAddFunc(t *testing.T) {
+	r := newRouter(t)
+	defer r.Close()
+	_, err := r.AddFunc("count", "^count$", func(in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) error {
+		for _, b := range []string{"one", "two", "three"} {
+			if err := send(&botrpc.ChatMessage{Body: b}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AddFunc: %v", err)
+	}
+	r.Expect(t, message("count"), "one", "two", "three")
+	r.Expect(t, message("count again"))
+}
+
+func TestStorage(t *testing.T) {
+	r := newRouter(t)
+	defer r.Close()
+	st, err := r.AddFunc("remember", "^remember", func(in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AddFunc: %v", err)
+	}
+	token := st.Funcs[0].Token
+	ctx := context.Background()
+	if _, err := r.Storage.Set(ctx, &botrpc.StorageItem{Token: token, Key: "k", Value: []byte("v")}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	item, err := r.Storage.Get(ctx, &botrpc.StorageKey{Token: token, Key: "k"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(item.Value) != "v" {
+		t.Errorf("Get = %q, want %q", item.Value, "v")
+	}
+	if _, err := r.Storage.Get(ctx, &botrpc.StorageKey{Token: "bogus", Key: "k"}); err == nil {
+		t.Errorf("Get with an invalid token succeeded")
+	}
+}
+
+func TestClose(t *testing.T) {
+	r := newRouter(t)
+	if _, err := r.AddFunc("ping", "^ping$", func(in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) error {
+		return send(&botrpc.ChatMessage{Body: "pong"})
+	}); err != nil {
+		t.Fatalf("AddFunc: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// the next router starts without the funcs of the last one.
+	r = newRouter(t)
+	defer r.Close()
+	r.Expect(t, message("ping"))
+}
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -40,6 +43,7 @@ var config = struct {
 	lastMsgTimestamp time.Time
 	address          string
 	client           botrpc.BotClient
+	integration      *botrpc.Integration
 }{
 	lastMsgTimestamp: time.Now(),
 }
@@ -64,6 +68,10 @@ func main() {
 	url := callRTMStart()
 	config.ws = createWSConn(url)
 
+	if err := registerWithChatbot(); err != nil {
+		log.Fatal(err)
+	}
+
 	errorChan := make(chan error)
 	listenCtx, listenCancel := context.WithCancel(context.Background())
 	go func() {
@@ -75,6 +83,11 @@ func main() {
 		defer pingerCancel()
 		errorChan <- pinger(pingerCtx)
 	}()
+	subscribeCtx, subscribeCancel := context.WithCancel(context.Background())
+	go func() {
+		defer subscribeCancel()
+		errorChan <- subscribe(subscribeCtx)
+	}()
 
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
@@ -90,6 +103,7 @@ func main() {
 			config.ws.Close()
 			listenCancel()
 			pingerCancel()
+			subscribeCancel()
 			os.Exit(0)
 		}
 	}
@@ -104,6 +118,28 @@ func connectToChatbot() error {
 	return nil
 }
 
+// registerWithChatbot registers the slack team as an integration.
+func registerWithChatbot() error {
+	i, err := config.client.Register(context.Background(), &botrpc.Integration{
+		Name:            "slack",
+		Workspace:       config.team.Domain,
+		ProtocolVersion: botrpc.ProtocolVersion,
+		Capabilities: []botrpc.Capability{
+			botrpc.Capability_RICH_CONTENT,
+			botrpc.Capability_THREADS,
+			botrpc.Capability_EPHEMERAL,
+			botrpc.Capability_DIRECT_MESSAGES,
+			botrpc.Capability_REACTIONS,
+			botrpc.Capability_EVENTS,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("registering with chatbot: %v", err)
+	}
+	config.integration = i
+	return nil
+}
+
 func callRTMStart() string {
 	resp, err := http.Get(fmt.Sprintf("https://slack.com/api/rtm.start?token=%v", config.token))
 	if err != nil {
@@ -162,6 +198,24 @@ func pinger(ctx context.Context) error {
 	return nil
 }
 
+// subscribe receives the messages bots post to chatbot and sends them to slack.
+func subscribe(ctx context.Context) error {
+	stream, err := config.client.Subscribe(ctx, config.integration)
+	if err != nil {
+		return fmt.Errorf("subscribing to chatbot: %v", err)
+	}
+	for {
+		m, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("receiving posted messages: %v", err)
+		}
+		log.Printf("received post from %v: %v", m.FuncName, m.Body)
+		if err := sendMessage(m); err != nil {
+			log.Printf("error sending message to slack: %v", err)
+		}
+	}
+}
+
 func listen(ctx context.Context) error {
 	ch := make(chan string)
 	go func() {
@@ -201,18 +255,26 @@ func handleMsg(msg string) error {
 		return handleError(msg)
 	case "message":
 		handleMessage(msg)
+	case "reaction_added", "reaction_removed":
+		handleReaction(msg)
 	default:
+		if t, ok := slackEvents[msgType.Type]; ok {
+			handleEvent(t, msg)
+			break
+		}
 		log.Println(msg)
 	}
 	return nil
 }
 
 type slackMessage struct {
-	Channel string `json:"channel,omitempty"`
-	User    string `json:"user,omitempty"`
-	Text    string `json:"text,omitempty"`
-	Ts      string `json:"ts,omitempty"`
-	Type    string `json:"type"`
+	Channel  string `json:"channel,omitempty"`
+	User     string `json:"user,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Ts       string `json:"ts,omitempty"`
+	ThreadTs string `json:"thread_ts,omitempty"`
+	Type     string `json:"type"`
+	Subtype  string `json:"subtype,omitempty"`
 }
 
 func handleMessage(msg string) error {
@@ -220,12 +282,142 @@ func handleMessage(msg string) error {
 	if err := json.Unmarshal([]byte(msg), &sm); err != nil {
 		return err
 	}
+	if t, ok := slackEvents[sm.Subtype]; ok {
+		return handleEvent(t, msg)
+	}
+	text, mentioned := stripMention(sm.Text)
 	m := &botrpc.ChatMessage{
-		Body:    sm.Text,
-		User:    sm.User,
-		Channel: sm.Channel,
+		Body:      text,
+		User:      sm.User,
+		Channel:   sm.Channel,
+		ThreadId:  sm.ThreadTs,
+		MessageId: sm.Ts,
+		// direct message channel ids start with D.
+		Addressed: mentioned || strings.HasPrefix(sm.Channel, "D"),
 	}
 	log.Printf("sending to chatbot: %v\n", m.Body)
+	return sendToChatbot(m)
+}
+
+// stripMention removes a mention of the bot from the start of text. It reports
+// whether there was one.
+func stripMention(text string) (string, bool) {
+	prefix := "<@" + config.self.ID
+	if !strings.HasPrefix(text, prefix+">") && !strings.HasPrefix(text, prefix+"|") {
+		return text, false
+	}
+	i := strings.Index(text, ">")
+	if i < 0 {
+		return text, false
+	}
+	text = strings.TrimPrefix(text[i+1:], ":")
+	return strings.TrimSpace(text), true
+}
+
+type slackReaction struct {
+	Type     string `json:"type"`
+	User     string `json:"user"`
+	Reaction string `json:"reaction"`
+	Item     struct {
+		Type    string `json:"type"`
+		Channel string `json:"channel"`
+		Ts      string `json:"ts"`
+	} `json:"item"`
+}
+
+func handleReaction(msg string) error {
+	var sr slackReaction
+	if err := json.Unmarshal([]byte(msg), &sr); err != nil {
+		return err
+	}
+	// only reactions to messages can be answered, and reacting to our own
+	// reactions would loop.
+	if sr.Item.Type != "message" || sr.User == config.self.ID {
+		return nil
+	}
+	r := &botrpc.Reaction{Name: sr.Reaction}
+	if sr.Type == "reaction_removed" {
+		r.Action = botrpc.Reaction_REMOVE
+	}
+	m := &botrpc.ChatMessage{
+		User:      sr.User,
+		Channel:   sr.Item.Channel,
+		MessageId: sr.Item.Ts,
+		Reaction:  r,
+	}
+	log.Printf("sending reaction to chatbot: %v\n", r.Name)
+	return sendToChatbot(m)
+}
+
+// slackEvents maps slack event types and message subtypes to chat events.
+var slackEvents = map[string]botrpc.ChatEvent_Type{
+	"member_joined_channel": botrpc.ChatEvent_MEMBER_JOINED,
+	"member_left_channel":   botrpc.ChatEvent_MEMBER_LEFT,
+	"channel_created":       botrpc.ChatEvent_CHANNEL_CREATED,
+	"channel_topic":         botrpc.ChatEvent_TOPIC_CHANGED,
+	"presence_change":       botrpc.ChatEvent_PRESENCE_CHANGED,
+	"message_changed":       botrpc.ChatEvent_MESSAGE_EDITED,
+	"message_deleted":       botrpc.ChatEvent_MESSAGE_DELETED,
+}
+
+type slackEvent struct {
+	User            string       `json:"user"`
+	Channel         string       `json:"channel"`
+	Topic           string       `json:"topic"`
+	Presence        string       `json:"presence"`
+	DeletedTs       string       `json:"deleted_ts"`
+	EventTs         string       `json:"event_ts"`
+	Message         slackMessage `json:"message"`
+	PreviousMessage slackMessage `json:"previous_message"`
+}
+
+func handleEvent(t botrpc.ChatEvent_Type, msg string) error {
+	e := &botrpc.ChatEvent{Type: t}
+	m := &botrpc.ChatMessage{Event: e}
+	if t == botrpc.ChatEvent_CHANNEL_CREATED {
+		// channel_created is the only event where channel is an object.
+		var cc struct {
+			Channel struct {
+				ID      string `json:"id"`
+				Name    string `json:"name"`
+				Creator string `json:"creator"`
+			} `json:"channel"`
+			EventTs string `json:"event_ts"`
+		}
+		if err := json.Unmarshal([]byte(msg), &cc); err != nil {
+			return err
+		}
+		m.Channel, m.User, e.Text = cc.Channel.ID, cc.Channel.Creator, cc.Channel.Name
+		m.MessageId = cc.EventTs
+		return sendToChatbot(m)
+	}
+
+	var se slackEvent
+	if err := json.Unmarshal([]byte(msg), &se); err != nil {
+		return err
+	}
+	// event_ts identifies the event so the router handles it once even when
+	// several integrations receive it.
+	m.User, m.Channel, m.MessageId = se.User, se.Channel, se.EventTs
+	switch t {
+	case botrpc.ChatEvent_TOPIC_CHANGED:
+		e.Text = se.Topic
+	case botrpc.ChatEvent_PRESENCE_CHANGED:
+		e.Text = se.Presence
+	case botrpc.ChatEvent_MESSAGE_EDITED:
+		m.User, m.MessageId, m.ThreadId = se.Message.User, se.Message.Ts, se.Message.ThreadTs
+		e.Text, e.PreviousText = se.Message.Text, se.PreviousMessage.Text
+	case botrpc.ChatEvent_MESSAGE_DELETED:
+		m.User, m.MessageId = se.PreviousMessage.User, se.DeletedTs
+		e.PreviousText = se.PreviousMessage.Text
+	}
+	log.Printf("sending event to chatbot: %v\n", t)
+	return sendToChatbot(m)
+}
+
+// sendToChatbot sends m to chatbot and delivers all the responses to slack.
+func sendToChatbot(m *botrpc.ChatMessage) error {
+	m.Source = config.integration.Id
 	stream, err := config.client.SendMessage(context.Background(), m)
 	if err != nil {
 		return err
@@ -239,11 +431,196 @@ func handleMessage(msg string) error {
 			return err
 		}
 		log.Printf("received response from chatbot: %v", in.Body)
-		websocket.JSON.Send(config.ws, slackMessage{Type: "message", Channel: in.Channel, Text: in.Body})
+		if err := sendMessage(in); err != nil {
+			log.Printf("error sending message to slack: %v", err)
+		}
 	}
 	return nil
 }
 
+// slackAttachment is the slack representation of a botrpc.Attachment.
+type slackAttachment struct {
+	Fallback  string       `json:"fallback"`
+	Color     string       `json:"color,omitempty"`
+	Title     string       `json:"title,omitempty"`
+	TitleLink string       `json:"title_link,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Fields    []slackField `json:"fields,omitempty"`
+	ImageURL  string       `json:"image_url,omitempty"`
+	ThumbURL  string       `json:"thumb_url,omitempty"`
+	Footer    string       `json:"footer,omitempty"`
+	MrkdwnIn  []string     `json:"mrkdwn_in,omitempty"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func toSlackAttachment(a *botrpc.Attachment) slackAttachment {
+	sa := slackAttachment{
+		Fallback:  a.PlainText(),
+		Color:     a.Color,
+		Title:     a.Title,
+		TitleLink: a.TitleLink,
+		Text:      a.Text,
+		ImageURL:  a.ImageUrl,
+		ThumbURL:  a.ThumbUrl,
+		Footer:    a.Footer,
+	}
+	for _, f := range a.Fields {
+		sa.Fields = append(sa.Fields, slackField{Title: f.Title, Value: f.Value, Short: f.Short})
+	}
+	if a.Markdown {
+		sa.MrkdwnIn = []string{"text", "fields"}
+	}
+	if a.Code != "" {
+		if sa.Text != "" {
+			sa.Text += "\n"
+		}
+		sa.Text += "```" + a.Code + "```"
+		if !a.Markdown {
+			sa.MrkdwnIn = []string{"text"}
+		}
+	}
+	return sa
+}
+
+// sendMessage delivers a response from chatbot to slack. Public plain text goes
+// over the rtm websocket, everything else has to use the web api.
+func sendMessage(m *botrpc.ChatMessage) error {
+	if m.Reaction != nil {
+		return react(m)
+	}
+	channel, ts := m.Channel, threadTs(m)
+	switch m.Visibility {
+	case botrpc.ChatMessage_EPHEMERAL:
+		params, err := messageParams(m, channel, ts)
+		if err != nil {
+			return err
+		}
+		params.Set("user", m.User)
+		return callAPI("chat.postEphemeral", params, nil)
+	case botrpc.ChatMessage_DIRECT:
+		im, err := openIM(m.User)
+		if err != nil {
+			return err
+		}
+		channel, ts = im, ""
+	}
+	// the rtm api only accepts channel ids, posts by name need the web api.
+	if len(m.Attachments) == 0 && !strings.HasPrefix(channel, "#") {
+		return websocket.JSON.Send(config.ws, slackMessage{
+			Type:     "message",
+			Channel:  channel,
+			Text:     m.Body,
+			ThreadTs: ts,
+		})
+	}
+	params, err := messageParams(m, channel, ts)
+	if err != nil {
+		return err
+	}
+	return callAPI("chat.postMessage", params, nil)
+}
+
+// messageParams creates the web api parameters for posting m to channel.
+func messageParams(m *botrpc.ChatMessage, channel, ts string) (url.Values, error) {
+	params := url.Values{
+		"channel": {channel},
+		"text":    {m.Body},
+		"as_user": {"true"},
+	}
+	if ts != "" {
+		params.Set("thread_ts", ts)
+	}
+	if len(m.Attachments) > 0 {
+		var atts []slackAttachment
+		for _, a := range m.Attachments {
+			atts = append(atts, toSlackAttachment(a))
+		}
+		b, err := json.Marshal(atts)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("attachments", string(b))
+	}
+	return params, nil
+}
+
+// react adds or removes the reaction in m on the message it refers to.
+func react(m *botrpc.ChatMessage) error {
+	method := "reactions.add"
+	if m.Reaction.Action == botrpc.Reaction_REMOVE {
+		method = "reactions.remove"
+	}
+	return callAPI(method, url.Values{
+		"name":      {m.Reaction.Name},
+		"channel":   {m.Channel},
+		"timestamp": {m.MessageId},
+	}, nil)
+}
+
+// openIM returns the direct message channel for user.
+func openIM(user string) (string, error) {
+	var r struct {
+		Channel struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	}
+	if err := callAPI("im.open", url.Values{"user": {user}}, &r); err != nil {
+		return "", err
+	}
+	return r.Channel.ID, nil
+}
+
+// threadTs returns the thread a response should be posted in. By default
+// responses go to the thread the message came from.
+func threadTs(m *botrpc.ChatMessage) string {
+	switch m.ThreadReply {
+	case botrpc.ChatMessage_THREAD:
+		if m.ThreadId != "" {
+			return m.ThreadId
+		}
+		return m.MessageId
+	case botrpc.ChatMessage_CHANNEL:
+		return ""
+	default:
+		return m.ThreadId
+	}
+}
+
+// callAPI calls a slack web api method and checks the response for errors. If
+// v is not nil the response is also decoded into it.
+func callAPI(method string, params url.Values, v interface{}) error {
+	params.Set("token", config.token)
+	resp, err := http.PostForm("https://slack.com/api/"+method, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var r struct {
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return fmt.Errorf("decoding %v response: %v", method, err)
+	}
+	if !r.Ok {
+		return fmt.Errorf("%v did not return ok: %v", method, r.Error)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
+
 func handleError(msg string) error {
 	type slackError struct {
 		Code    int    `json:"code"`
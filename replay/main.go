@@ -0,0 +1,150 @@
+// Command replay sends the messages of a transcript recorded by the router
+// with CHATBOT_TRANSCRIPT_FILE to a router and reports every message whose
+// responses differ from the recording. The bots are really called again, so
+// don't replay transcripts with commands that change things.
+//
+//	CHATBOT_ADDR=localhost:8173 replay chatbot-transcript.jsonl
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+var config = struct {
+	addr string
+}{
+	addr: "localhost:8173",
+}
+
+func main() {
+	if addr := os.Getenv("CHATBOT_ADDR"); addr != "" {
+		config.addr = addr
+	}
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: replay transcript\n")
+		os.Exit(2)
+	}
+	entries, err := readTranscript(os.Args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading transcript: %v\n", err)
+		os.Exit(1)
+	}
+	conn, err := grpc.Dial(config.addr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error connecting with chatbot: %v\n", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	c := botrpc.NewBotClient(conn)
+
+	differ := 0
+	for i, e := range entries {
+		m := e.Message
+		got, err := send(c, m)
+		d := diff(texts(e.Responses), texts(got))
+		if err != nil {
+			d = fmt.Sprintf("  error: %v\n", err)
+		}
+		if d == "" {
+			continue
+		}
+		differ++
+		fmt.Printf("message %d from %v in %v: %q\n%v", i+1, m.User, m.Channel, m.Body, d)
+	}
+	fmt.Printf("%d of %d messages differ\n", differ, len(entries))
+	if differ > 0 {
+		os.Exit(1)
+	}
+}
+
+// readTranscript reads the entries of the transcript at path.
+func readTranscript(path string) ([]*botrpc.TranscriptEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []*botrpc.TranscriptEntry
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		e := &botrpc.TranscriptEntry{}
+		if err := json.Unmarshal(s.Bytes(), e); err != nil {
+			return nil, err
+		}
+		if e.Message != nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, s.Err()
+}
+
+// send sends m to the router and returns the responses.
+func send(c botrpc.BotClient, m *botrpc.ChatMessage) ([]*botrpc.ChatMessage, error) {
+	stream, err := c.SendMessage(context.Background(), m)
+	if err != nil {
+		return nil, err
+	}
+	var out []*botrpc.ChatMessage
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, r)
+	}
+}
+
+// texts returns the responses as text, sorted so funcs answering in a
+// different order don't count as a difference.
+func texts(ms []*botrpc.ChatMessage) []string {
+	var t []string
+	for _, m := range ms {
+		s := m.PlainText()
+		if m.Reaction != nil {
+			s = fmt.Sprintf("%v :%v:", m.Reaction.Action, m.Reaction.Name)
+		}
+		t = append(t, s)
+	}
+	sort.Strings(t)
+	return t
+}
+
+// diff lists the recorded responses missing from the replayed ones with a -
+// and the replayed responses that weren't recorded with a +.
+func diff(recorded, replayed []string) string {
+	count := make(map[string]int)
+	for _, s := range replayed {
+		count[s]++
+	}
+	var buf bytes.Buffer
+	for _, s := range recorded {
+		if count[s] > 0 {
+			count[s]--
+			continue
+		}
+		fmt.Fprintf(&buf, "  - %q\n", s)
+	}
+	for _, s := range replayed {
+		if count[s] > 0 {
+			count[s]--
+			fmt.Fprintf(&buf, "  + %q\n", s)
+		}
+	}
+	return buf.String()
+}
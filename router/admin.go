@@ -0,0 +1,59 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// adminCommands are the commands for running the router by name. They get
+// the words following the command as args.
+var adminCommands = map[string]func(in *botrpc.ChatMessage, args []string, outStream botrpc.Bot_SendMessageServer){
+	"bots":      listBots,
+	"conflicts": conflictReport,
+	"removebot": removeBotCommand,
+}
+
+// readOnlyCommands are the admin commands that don't change anything.
+var readOnlyCommands = map[string]bool{
+	"bots":      true,
+	"conflicts": true,
+}
+
+// isAdmin reports whether the user of in may use the admin command cmd. When
+// CHATBOT_ADMINS isn't set everyone may use the read only commands and nobody
+// the others.
+func isAdmin(in *botrpc.ChatMessage, cmd string) bool {
+	if len(config.admins) == 0 {
+		return readOnlyCommands[cmd]
+	}
+	for _, a := range config.admins {
+		if a == in.User {
+			return true
+		}
+	}
+	return false
+}
+
+// handleAdmin runs the admin command in is addressed with. It reports whether
+// in was an admin command.
+func handleAdmin(in *botrpc.ChatMessage, outStream botrpc.Bot_SendMessageServer) bool {
+	if !addressed(in) {
+		return false
+	}
+	f := strings.Fields(strings.TrimPrefix(in.Body, config.commandPrefix))
+	if len(f) == 0 {
+		return false
+	}
+	name := strings.ToLower(f[0])
+	cmd, ok := adminCommands[name]
+	if !ok {
+		return false
+	}
+	if !isAdmin(in, name) {
+		sendNotice(in, outStream, "sorry, only admins can do that.")
+		return true
+	}
+	cmd(in, f[1:], outStream)
+	return true
+}
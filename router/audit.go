@@ -0,0 +1,203 @@
+package router
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// auditLog appends audit records to a file of JSON lines. When the file grows
+// past maxSize it is renamed with the time appended and a new one is started.
+// Records are never changed or removed.
+type auditLog struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	f       *os.File
+	size    int64
+}
+
+// audit is the audit log configured with CHATBOT_AUDIT_FILE. It is nil when
+// auditing is off.
+var audit *auditLog
+
+// openAuditLog opens the audit log at path for appending.
+func openAuditLog(path string, maxSize int64) (*auditLog, error) {
+	a := &auditLog{path: path, maxSize: maxSize}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *auditLog) open() error {
+	f, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	a.f, a.size = f, fi.Size()
+	return nil
+}
+
+// rotate moves the current file aside and starts a new one. The caller must
+// hold a.mu.
+func (a *auditLog) rotate() error {
+	if err := a.f.Close(); err != nil {
+		return err
+	}
+	rotated := a.path + "." + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(a.path, rotated); err != nil {
+		return err
+	}
+	return a.open()
+}
+
+// append writes r as a line to the log.
+func (a *auditLog) append(r *botrpc.AuditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.maxSize > 0 && a.size > 0 && a.size+int64(len(b)) > a.maxSize {
+		if err := a.rotate(); err != nil {
+			return fmt.Errorf("rotating audit log: %v", err)
+		}
+	}
+	n, err := a.f.Write(b)
+	a.size += int64(n)
+	return err
+}
+
+// files returns the rotated files followed by the current one, oldest first.
+func (a *auditLog) files() ([]string, error) {
+	rotated, err := filepath.Glob(a.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(rotated)
+	return append(rotated, a.path), nil
+}
+
+// query calls fn with the records matching q, oldest first, until fn returns
+// an error.
+func (a *auditLog) query(q *botrpc.AuditQuery, fn func(*botrpc.AuditRecord) error) error {
+	files, err := a.files()
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if err := queryFile(name, q, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryFile(name string, q *botrpc.AuditQuery, fn func(*botrpc.AuditRecord) error) error {
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		r := &botrpc.AuditRecord{}
+		if err := json.Unmarshal(s.Bytes(), r); err != nil {
+			return fmt.Errorf("reading %v: %v", name, err)
+		}
+		if auditMatches(q, r) {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Err()
+}
+
+// auditMatches reports whether r is selected by q.
+func auditMatches(q *botrpc.AuditQuery, r *botrpc.AuditRecord) bool {
+	t := time.Unix(0, r.Time)
+	switch {
+	case q.User != "" && q.User != r.User:
+		return false
+	case q.Start != 0 && t.Before(time.Unix(q.Start, 0)):
+		return false
+	case q.End != 0 && t.After(time.Unix(q.End, 0)):
+		return false
+	case q.FuncName == "":
+		return true
+	}
+	for _, f := range r.Funcs {
+		if f == q.FuncName || strings.HasSuffix(f, "."+q.FuncName) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordAudit adds a record for the funcs called with in to the audit log.
+// errs are the errors of the funcs that failed.
+func recordAudit(in *botrpc.ChatMessage, funcs []string, start time.Time, errs []error) {
+	if audit == nil || len(funcs) == 0 {
+		return
+	}
+	r := &botrpc.AuditRecord{
+		Time:       start.UnixNano(),
+		MessageId:  in.MessageId,
+		Source:     in.Source,
+		User:       in.User,
+		Channel:    in.Channel,
+		Funcs:      funcs,
+		Outcome:    "ok",
+		DurationMs: int64(time.Since(start) / time.Millisecond),
+	}
+	if len(errs) > 0 {
+		var s []string
+		for _, err := range errs {
+			s = append(s, err.Error())
+		}
+		r.Outcome, r.Error = "error", strings.Join(s, "; ")
+	}
+	if err := audit.append(r); err != nil {
+		log.Printf("error writing audit log: %v", err)
+	}
+}
+
+// adminServer implements botrpc.AdminServer.
+type adminServer struct{}
+
+// QueryAudit streams the audit records matching the query. It is only
+// available with CHATBOT_ADMIN_TOKEN set and needs that token.
+func (s *adminServer) QueryAudit(in *botrpc.AuditQuery, stream botrpc.Admin_QueryAuditServer) error {
+	if config.adminToken == "" || in.Token != config.adminToken {
+		return grpc.Errorf(codes.PermissionDenied, "invalid admin token")
+	}
+	if audit == nil {
+		return grpc.Errorf(codes.FailedPrecondition, "the audit log is off")
+	}
+	return audit.query(in, stream.Send)
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// balancing policies for CHATBOT_BALANCE.
+const (
+	balanceRoundRobin    = "round-robin"     // take turns
+	balanceLeastInFlight = "least-in-flight" // prefer the replica with the fewest calls running
+)
+
+// replicaKey identifies the func cf is a replica of. Funcs of the same bot
+// with the same name are replicas running at different addresses. Funcs added
+// without a bot are only replicas of themselves.
+func (cf chatfunc) replicaKey() string {
+	if cf.bot == "" {
+		return cf.Addr + "/" + cf.FuncName
+	}
+	return cf.bot + "/" + cf.FuncName
+}
+
+// pools groups fs into pools of replicas in the order they were added.
+func pools(fs []chatfunc) [][]chatfunc {
+	var ps [][]chatfunc
+	index := make(map[string]int)
+	for _, cf := range fs {
+		k := cf.replicaKey()
+		i, ok := index[k]
+		if !ok {
+			i = len(ps)
+			index[k] = i
+			ps = append(ps, nil)
+		}
+		ps[i] = append(ps[i], cf)
+	}
+	return ps
+}
+
+// triggeredPools returns the funcs triggered by in grouped into pools.
+func triggeredPools(in *botrpc.ChatMessage) [][]chatfunc {
+	var fs []chatfunc
+	for _, cf := range funcs() {
+		if cf.triggered(in) {
+			fs = append(fs, cf)
+		}
+	}
+	return pools(fs)
+}
+
+// balancer keeps what the balancing policies need to pick a replica.
+var balancer = struct {
+	sync.Mutex
+	next     map[string]int // next replica to try by replicaKey
+	inFlight map[string]int // running calls by Addr
+}{next: make(map[string]int), inFlight: make(map[string]int)}
+
+// balance returns the replicas of pool in the order they should be tried.
+func balance(pool []chatfunc) []chatfunc {
+	if len(pool) == 1 {
+		return pool
+	}
+	balancer.Lock()
+	defer balancer.Unlock()
+	k := pool[0].replicaKey()
+	n := balancer.next[k] % len(pool)
+	balancer.next[k] = n + 1
+	order := append(append([]chatfunc(nil), pool[n:]...), pool[:n]...)
+	if config.balance == balanceLeastInFlight {
+		sort.SliceStable(order, func(i, j int) bool {
+			return balancer.inFlight[order[i].Addr] < balancer.inFlight[order[j].Addr]
+		})
+	}
+	return order
+}
+
+func trackInFlight(cf chatfunc, delta int) {
+	balancer.Lock()
+	defer balancer.Unlock()
+	balancer.inFlight[cf.Addr] += delta
+	if balancer.inFlight[cf.Addr] <= 0 {
+		delete(balancer.inFlight, cf.Addr)
+	}
+}
+
+// callPool calls one replica of pool and hands every response to send along
+// with the replica that sent it. When a replica fails before responding the
+// next one is tried. The error of the last replica tried is returned.
+func callPool(pool []chatfunc, in *botrpc.ChatMessage, send func(cf chatfunc, out *botrpc.ChatMessage) error) error {
+	var err error
+	for _, cf := range balance(pool) {
+		cf := cf
+		responded := false
+		trackInFlight(cf, 1)
+		err = callFunc(cf, in, func(out *botrpc.ChatMessage) error {
+			responded = true
+			return send(cf, out)
+		})
+		trackInFlight(cf, -1)
+		// a replica that already responded can't be retried without
+		// repeating the responses.
+		if err == nil || responded {
+			return err
+		}
+		if len(pool) > 1 {
+			log.Printf("error calling %v at %v, trying another replica: %v", cf.FuncName, cf.Addr, err)
+		}
+	}
+	return err
+}
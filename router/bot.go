@@ -0,0 +1,183 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+	"text/tabwriter"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// bots contains the bots added with RegisterBot by name, without their funcs.
+// It is guarded by chatFuncsMu so a bot and its funcs always change together.
+var bots = make(map[string]*botrpc.BotInfo)
+
+// RegisterBot adds all the funcs of the bot or none of them. The funcs of an
+// earlier registration of the bot at the same addresses are replaced. Funcs
+// at other addresses are kept as replicas. If the conflict policy rejects any
+// of the funcs, the status is ERROR and lists the conflicts of each func.
+func (s *server) RegisterBot(ctx context.Context, in *botrpc.BotInfo) (*botrpc.BotStatus, error) {
+	if in.Name == "" {
+		return &botrpc.BotStatus{Status: botrpc.FuncStatus_ERROR}, fmt.Errorf("bot needs a name")
+	}
+	if len(in.Funcs) == 0 {
+		return &botrpc.BotStatus{Status: botrpc.FuncStatus_ERROR}, fmt.Errorf("bot %v has no funcs", in.Name)
+	}
+	cfs := make([]chatfunc, 0, len(in.Funcs))
+	addrs := make(map[string]bool)
+	for _, f := range in.Funcs {
+		cf, err := newChatfunc(f)
+		if err != nil {
+			return &botrpc.BotStatus{Status: botrpc.FuncStatus_ERROR}, fmt.Errorf("bot %v: %v", in.Name, err)
+		}
+		cf.bot = in.Name
+		cfs = append(cfs, cf)
+		addrs[f.Addr] = true
+	}
+	replaced := func(cf chatfunc) bool { return cf.bot == in.Name && addrs[cf.Addr] }
+
+	chatFuncsMu.Lock()
+	defer chatFuncsMu.Unlock()
+	// the funcs of an earlier registration are being replaced so they can't
+	// conflict.
+	var others []chatfunc
+	for _, cf := range chatFuncs {
+		if !replaced(cf) {
+			others = append(others, cf)
+		}
+	}
+	st := &botrpc.BotStatus{Status: botrpc.FuncStatus_OK}
+	for i, cf := range cfs {
+		warnings, err := checkConflicts(cf, append(others[:len(others):len(others)], cfs[:i]...))
+		fst := &botrpc.FuncStatus{
+			Status:          botrpc.FuncStatus_OK,
+			Warnings:        warnings,
+			ProtocolVersion: botrpc.ProtocolVersion,
+		}
+		if err != nil {
+			log.Printf("error registering bot %v: %v", in.Name, err)
+			fst.Status, st.Status = botrpc.FuncStatus_ERROR, botrpc.FuncStatus_ERROR
+		}
+		st.Funcs = append(st.Funcs, fst)
+	}
+	if st.Status == botrpc.FuncStatus_ERROR {
+		// the conflicts are only readable by the bot in a response without
+		// an error.
+		return st, nil
+	}
+	for i := range cfs {
+		token, err := newToken(cfs[i])
+		if err != nil {
+			return &botrpc.BotStatus{Status: botrpc.FuncStatus_ERROR}, err
+		}
+		cfs[i].token, st.Funcs[i].Token = token, token
+	}
+	publishRemoved(removeFuncs(replaced))
+	chatFuncs = append(chatFuncs, cfs...)
+	b := *in
+	b.Funcs = nil
+	bots[in.Name] = &b
+	publishAdded(cfs...)
+	log.Printf("registered bot %v %v with %d funcs", in.Name, in.Version, len(cfs))
+	return st, nil
+}
+
+// RemoveBot removes the bot named in BotInfo and all its funcs.
+func (s *server) RemoveBot(ctx context.Context, in *botrpc.BotInfo) (*botrpc.BotStatus, error) {
+	if !removeBot(in.Name) {
+		return &botrpc.BotStatus{Status: botrpc.FuncStatus_ERROR}, grpc.Errorf(codes.NotFound, "bot %v not found", in.Name)
+	}
+	return &botrpc.BotStatus{Status: botrpc.FuncStatus_OK}, nil
+}
+
+// removeBot removes the bot with name and the funcs of all its replicas. It
+// reports whether the bot was registered.
+func removeBot(name string) bool {
+	chatFuncsMu.Lock()
+	defer chatFuncsMu.Unlock()
+	if _, ok := bots[name]; !ok {
+		return false
+	}
+	publishRemoved(removeFuncs(func(cf chatfunc) bool { return cf.bot == name }))
+	delete(bots, name)
+	log.Printf("removed bot %v", name)
+	return true
+}
+
+// removeReplica removes the funcs of the bot with name at addr. The bot is
+// removed with its last replica.
+func removeReplica(name, addr string) {
+	chatFuncsMu.Lock()
+	defer chatFuncsMu.Unlock()
+	publishRemoved(removeFuncs(func(cf chatfunc) bool { return cf.bot == name && cf.Addr == addr }))
+	if _, ok := bots[name]; !ok {
+		return
+	}
+	for _, cf := range chatFuncs {
+		if cf.bot == name {
+			return
+		}
+	}
+	delete(bots, name)
+	log.Printf("removed bot %v", name)
+}
+
+// registeredBots returns the registered bots sorted by name.
+func registeredBots() []*botrpc.BotInfo {
+	chatFuncsMu.RLock()
+	defer chatFuncsMu.RUnlock()
+	var bs []*botrpc.BotInfo
+	for _, b := range bots {
+		bs = append(bs, b)
+	}
+	sort.Slice(bs, func(i, j int) bool { return bs[i].Name < bs[j].Name })
+	return bs
+}
+
+// listBots is the admin command that lists the registered bots.
+func listBots(in *botrpc.ChatMessage, args []string, outStream botrpc.Bot_SendMessageServer) {
+	bs := registeredBots()
+	if len(bs) == 0 {
+		sendNotice(in, outStream, "no bots are registered.")
+		return
+	}
+	names := make(map[string]map[string]bool)
+	addrs := make(map[string]map[string]bool)
+	for _, cf := range funcs() {
+		if names[cf.bot] == nil {
+			names[cf.bot], addrs[cf.bot] = make(map[string]bool), make(map[string]bool)
+		}
+		names[cf.bot][cf.FuncName] = true
+		addrs[cf.bot][cf.Addr] = true
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(w, "bot\tversion\towner\tfuncs\treplicas\tdescription\n")
+	for _, b := range bs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", b.Name, b.Version, b.Owner, len(names[b.Name]), len(addrs[b.Name]), b.Description)
+	}
+	w.Flush()
+	sendNotice(in, outStream, buf.String())
+}
+
+// removeBotCommand is the admin command that removes the bots named in args.
+func removeBotCommand(in *botrpc.ChatMessage, args []string, outStream botrpc.Bot_SendMessageServer) {
+	if len(args) == 0 {
+		sendNotice(in, outStream, "usage: removebot name...")
+		return
+	}
+	for _, name := range args {
+		if !removeBot(name) {
+			sendNotice(in, outStream, fmt.Sprintf("there is no bot %v.", name))
+			continue
+		}
+		sendNotice(in, outStream, fmt.Sprintf("ok, removed %v.", name))
+	}
+}
@@ -0,0 +1,150 @@
+package router
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// checkVersion returns an error for peers speaking a protocol version the
+// router doesn't. Version 0 is a peer from before versioning.
+func checkVersion(v uint32) error {
+	switch {
+	case v > botrpc.ProtocolVersion:
+		return grpc.Errorf(codes.FailedPrecondition, "protocol version %d is newer than the router's %d, upgrade the router", v, botrpc.ProtocolVersion)
+	case v != 0 && v < botrpc.MinProtocolVersion:
+		return grpc.Errorf(codes.FailedPrecondition, "protocol version %d is older than %d, upgrade botrpc", v, botrpc.MinProtocolVersion)
+	}
+	return nil
+}
+
+// negotiate returns the protocol version the router and a peer with version v
+// speak.
+func negotiate(v uint32) uint32 {
+	if v == 0 {
+		return 1
+	}
+	if v < botrpc.ProtocolVersion {
+		return v
+	}
+	return botrpc.ProtocolVersion
+}
+
+func hasCapability(caps []botrpc.Capability, c botrpc.Capability) bool {
+	for _, cc := range caps {
+		if cc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFuncCapabilities returns an error if the func f wants messages it
+// declared it can't handle. Funcs from before versioning aren't checked.
+func checkFuncCapabilities(f *botrpc.Func) error {
+	if err := checkVersion(f.ProtocolVersion); err != nil {
+		return err
+	}
+	if f.ProtocolVersion < 2 {
+		return nil
+	}
+	if len(f.Events) > 0 && !hasCapability(f.Capabilities, botrpc.Capability_EVENTS) {
+		return grpc.Errorf(codes.InvalidArgument, "func %v subscribes to events without the EVENTS capability", f.FuncName)
+	}
+	if f.ReactionTrigger != "" && !hasCapability(f.Capabilities, botrpc.Capability_REACTIONS) {
+		return grpc.Errorf(codes.InvalidArgument, "func %v has a reaction trigger without the REACTIONS capability", f.FuncName)
+	}
+	return nil
+}
+
+// adaptForFunc returns in as the func cf can handle it. Rich content is
+// flattened to text and threads are left out for funcs that didn't declare
+// them.
+func adaptForFunc(cf chatfunc, in *botrpc.ChatMessage) *botrpc.ChatMessage {
+	if cf.ProtocolVersion < 2 {
+		return in
+	}
+	m := *in
+	if len(m.Attachments) > 0 && !hasCapability(cf.Capabilities, botrpc.Capability_RICH_CONTENT) {
+		m.Body, m.Attachments = m.PlainText(), nil
+	}
+	if !hasCapability(cf.Capabilities, botrpc.Capability_THREADS) {
+		m.ThreadId, m.ThreadReply = "", botrpc.ChatMessage_SAME
+	}
+	return &m
+}
+
+// holdUpdates returns send changed for the integration of in. Integrations
+// that can't show updates only get the last of a run of updates, once the
+// func sends something that isn't an update or flush is called when it is
+// done. Integrations that never registered get every update.
+func holdUpdates(in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) (hold func(*botrpc.ChatMessage) error, flush func() error) {
+	i, ok := integration(in.Source)
+	if !ok || hasCapability(i.Capabilities, botrpc.Capability_STREAMING_UPDATES) {
+		return send, func() error { return nil }
+	}
+	var held *botrpc.ChatMessage
+	flush = func() error {
+		if held == nil {
+			return nil
+		}
+		m := *held
+		m.Update, held = false, nil
+		return send(&m)
+	}
+	hold = func(out *botrpc.ChatMessage) error {
+		if out.Update {
+			held = out
+			return nil
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		return send(out)
+	}
+	return hold, flush
+}
+
+// integration returns the registered integration source refers to.
+func integration(source string) (*botrpc.Integration, bool) {
+	integrations.Lock()
+	defer integrations.Unlock()
+	if i, ok := integrations.m[source]; ok {
+		return i, true
+	}
+	for _, i := range integrations.m {
+		if i.Name == source {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+// adaptForIntegration returns m as the integration i can display it, or nil
+// if it can't be displayed at all. Ephemeral responses fall back to direct
+// messages and then to public ones. m isn't changed so it can be sent to
+// several integrations. Integrations that never registered get m as it is.
+func adaptForIntegration(i *botrpc.Integration, m *botrpc.ChatMessage) *botrpc.ChatMessage {
+	if i == nil {
+		return m
+	}
+	caps := i.Capabilities
+	if m.Reaction != nil && !hasCapability(caps, botrpc.Capability_REACTIONS) {
+		return nil
+	}
+	a := *m
+	if len(a.Attachments) > 0 && !hasCapability(caps, botrpc.Capability_RICH_CONTENT) {
+		a.Body, a.Attachments = a.PlainText(), nil
+	}
+	if !hasCapability(caps, botrpc.Capability_THREADS) {
+		a.ThreadId, a.ThreadReply = "", botrpc.ChatMessage_SAME
+	}
+	if a.Visibility == botrpc.ChatMessage_EPHEMERAL && !hasCapability(caps, botrpc.Capability_EPHEMERAL) {
+		a.Visibility = botrpc.ChatMessage_DIRECT
+	}
+	if a.Visibility == botrpc.ChatMessage_DIRECT && !hasCapability(caps, botrpc.Capability_DIRECT_MESSAGES) {
+		a.Visibility = botrpc.ChatMessage_PUBLIC
+	}
+	return &a
+}
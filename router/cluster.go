@@ -0,0 +1,468 @@
+package router
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+const (
+	// syncInterval is how often the instances exchange their registries.
+	syncInterval = 5 * time.Second
+	// claimTTL is how long an instance remembers the messages it granted.
+	// Integrations retrying a message later than that get it handled again.
+	claimTTL = 10 * time.Minute
+)
+
+// clustered reports whether the router runs as one instance of a cluster.
+func clustered() bool {
+	return len(config.clusterPeers) > 0
+}
+
+// clusterServer implements botrpc.ClusterServer.
+type clusterServer struct{}
+
+// metadata keys the instances authenticate each other with.
+const (
+	secretKey   = "chatbot-cluster-secret"
+	instanceKey = "chatbot-cluster-instance"
+)
+
+// peerCredentials adds the CHATBOT_CLUSTER_SECRET and the address of this
+// instance to the calls to other instances.
+type peerCredentials struct{}
+
+func (peerCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{secretKey: config.clusterSecret, instanceKey: config.clusterAddr}, nil
+}
+
+func (peerCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// validSecret reports whether md has the CHATBOT_CLUSTER_SECRET.
+func validSecret(md metadata.MD) bool {
+	v := md[secretKey]
+	return config.clusterSecret != "" && len(v) == 1 && subtle.ConstantTimeCompare([]byte(v[0]), []byte(config.clusterSecret)) == 1
+}
+
+// isPeer reports whether addr is one of CHATBOT_CLUSTER_PEERS.
+func isPeer(addr string) bool {
+	for _, p := range config.clusterPeers {
+		if p == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate returns the address of the instance calling, or an error
+// unless it is one of CHATBOT_CLUSTER_PEERS and knows the secret.
+func authenticate(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if !validSecret(md) {
+		return "", grpc.Errorf(codes.Unauthenticated, "invalid cluster secret")
+	}
+	if v := md[instanceKey]; len(v) == 1 && isPeer(v[0]) {
+		return v[0], nil
+	}
+	return "", grpc.Errorf(codes.PermissionDenied, "not an instance of the cluster")
+}
+
+// registry contains an entry for every func added to any instance, including
+// removed ones so the removal spreads to all instances.
+var registry = struct {
+	sync.Mutex
+	version int64
+	m       map[string]*botrpc.RegistryEntry
+}{m: make(map[string]*botrpc.RegistryEntry)}
+
+// entryKey identifies cf in the registry.
+func entryKey(cf chatfunc) string {
+	return cf.bot + "/" + cf.Addr + "/" + cf.FuncName
+}
+
+// nextVersion returns a version newer than any this instance handed out. The
+// caller must hold registry.
+func nextVersion() int64 {
+	v := time.Now().UnixNano()
+	if v <= registry.version {
+		v = registry.version + 1
+	}
+	registry.version = v
+	return v
+}
+
+// publishAdded records funcs added to this instance so they are synced to the
+// others. Bots connected with Connect can only be called by the instance they
+// are connected to and aren't published. The caller must hold chatFuncsMu.
+func publishAdded(cfs ...chatfunc) {
+	if !clustered() {
+		return
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	for _, cf := range cfs {
+		if _, ok := connectedBot(cf.Addr); ok {
+			continue
+		}
+		f := cf.Func
+		bot := &botrpc.BotInfo{Funcs: []*botrpc.Func{&f}}
+		if b, ok := bots[cf.bot]; ok {
+			bot.Name, bot.Version, bot.Owner, bot.Description = b.Name, b.Version, b.Owner, b.Description
+		}
+		registry.m[entryKey(cf)] = &botrpc.RegistryEntry{
+			Key:      entryKey(cf),
+			Version:  nextVersion(),
+			Instance: config.clusterAddr,
+			Bot:      bot,
+			Token:    cf.token,
+		}
+	}
+}
+
+// publishRemoved records funcs removed from this instance so they are removed
+// from the others too.
+func publishRemoved(cfs []chatfunc) {
+	if !clustered() {
+		return
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	for _, cf := range cfs {
+		if _, ok := registry.m[entryKey(cf)]; !ok {
+			continue
+		}
+		registry.m[entryKey(cf)] = &botrpc.RegistryEntry{
+			Key:      entryKey(cf),
+			Version:  nextVersion(),
+			Instance: config.clusterAddr,
+			Bot:      &botrpc.BotInfo{Name: cf.bot},
+			Removed:  true,
+		}
+	}
+}
+
+// newer reports whether a is a newer version of an entry than b.
+func newer(a, b *botrpc.RegistryEntry) bool {
+	if b == nil || a.Version != b.Version {
+		return b == nil || a.Version > b.Version
+	}
+	return a.Instance > b.Instance
+}
+
+// snapshot returns all the entries of the registry.
+func snapshot() *botrpc.Registry {
+	registry.Lock()
+	defer registry.Unlock()
+	r := &botrpc.Registry{Instance: config.clusterAddr}
+	for _, e := range registry.m {
+		r.Entries = append(r.Entries, e)
+	}
+	return r
+}
+
+// merge keeps the entries of r that are newer than the ones known and applies
+// them to the funcs.
+func merge(r *botrpc.Registry) {
+	var changed []*botrpc.RegistryEntry
+	registry.Lock()
+	for _, e := range r.Entries {
+		if newer(e, registry.m[e.Key]) {
+			registry.m[e.Key] = e
+			changed = append(changed, e)
+		}
+		if e.Version > registry.version {
+			registry.version = e.Version
+		}
+	}
+	registry.Unlock()
+	for _, e := range changed {
+		applyEntry(e)
+	}
+}
+
+// applyEntry replaces the func of the entry with the one it describes.
+func applyEntry(e *botrpc.RegistryEntry) {
+	chatFuncsMu.Lock()
+	defer chatFuncsMu.Unlock()
+	removeFuncs(func(cf chatfunc) bool { return entryKey(cf) == e.Key })
+	if e.Removed || e.Bot == nil || len(e.Bot.Funcs) != 1 {
+		if e.Bot != nil && e.Bot.Name != "" {
+			for _, cf := range chatFuncs {
+				if cf.bot == e.Bot.Name {
+					return
+				}
+			}
+			delete(bots, e.Bot.Name)
+		}
+		return
+	}
+	cf, err := newChatfunc(e.Bot.Funcs[0])
+	if err != nil {
+		log.Printf("error adding func from %v: %v", e.Instance, err)
+		return
+	}
+	cf.bot, cf.token = e.Bot.Name, e.Token
+	addToken(cf.token, cf, e.Instance)
+	chatFuncs = append(chatFuncs, cf)
+	if cf.bot != "" {
+		b := *e.Bot
+		b.Funcs = nil
+		bots[cf.bot] = &b
+	}
+}
+
+// Sync merges the registry of the instance calling and returns this one. The
+// secret is sent back in the header so the caller can authenticate this
+// instance before merging.
+func (s *clusterServer) Sync(ctx context.Context, in *botrpc.Registry) (*botrpc.Registry, error) {
+	instance, err := authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if in.Instance != instance {
+		return nil, grpc.Errorf(codes.PermissionDenied, "%v can't sync the registry of %v", instance, in.Instance)
+	}
+	merge(in)
+	markPeer(instance, true)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(secretKey, config.clusterSecret)); err != nil {
+		return nil, err
+	}
+	return snapshot(), nil
+}
+
+// peers contains the clients for the other instances and whether they
+// answered the last sync.
+var peers = struct {
+	sync.Mutex
+	conns map[string]*grpc.ClientConn
+	up    map[string]bool
+}{conns: make(map[string]*grpc.ClientConn), up: make(map[string]bool)}
+
+// peerClient returns a client for the instance at addr.
+func peerClient(addr string) (botrpc.ClusterClient, error) {
+	peers.Lock()
+	defer peers.Unlock()
+	conn, ok := peers.conns[addr]
+	if !ok {
+		var err error
+		if conn, err = grpc.Dial(addr, grpc.WithInsecure(), grpc.WithPerRPCCredentials(peerCredentials{})); err != nil {
+			return nil, err
+		}
+		peers.conns[addr] = conn
+	}
+	return botrpc.NewClusterClient(conn), nil
+}
+
+func markPeer(addr string, up bool) {
+	peers.Lock()
+	defer peers.Unlock()
+	if peers.up[addr] != up {
+		log.Printf("cluster instance %v up: %v", addr, up)
+	}
+	peers.up[addr] = up
+}
+
+func peerUp(addr string) bool {
+	peers.Lock()
+	defer peers.Unlock()
+	return peers.up[addr]
+}
+
+// runCluster syncs the registry with every other instance every syncInterval.
+// It never returns.
+func runCluster() {
+	for {
+		for _, addr := range config.clusterPeers {
+			syncPeer(addr)
+		}
+		time.Sleep(syncInterval)
+	}
+}
+
+func syncPeer(addr string) {
+	c, err := peerClient(addr)
+	if err != nil {
+		log.Printf("error connecting with instance %v: %v", addr, err)
+		markPeer(addr, false)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), syncInterval)
+	defer cancel()
+	var header metadata.MD
+	r, err := c.Sync(ctx, snapshot(), grpc.Header(&header))
+	if err != nil {
+		markPeer(addr, false)
+		return
+	}
+	if !validSecret(header) || r.Instance != addr {
+		log.Printf("error syncing with instance %v: it isn't an instance of the cluster", addr)
+		markPeer(addr, false)
+		return
+	}
+	markPeer(addr, true)
+	merge(r)
+}
+
+// claims contains the messages this instance granted by key.
+var claims = struct {
+	sync.Mutex
+	m      map[string]time.Time
+	pruned time.Time
+}{m: make(map[string]time.Time)}
+
+// claimLocal grants key if it wasn't granted in the last claimTTL.
+func claimLocal(key string) bool {
+	claims.Lock()
+	defer claims.Unlock()
+	now := time.Now()
+	if now.Sub(claims.pruned) > time.Minute {
+		for k, t := range claims.m {
+			if now.Sub(t) > claimTTL {
+				delete(claims.m, k)
+			}
+		}
+		claims.pruned = now
+	}
+	if t, ok := claims.m[key]; ok && now.Sub(t) <= claimTTL {
+		return false
+	}
+	claims.m[key] = now
+	return true
+}
+
+// Claim grants the message to the instance calling if no instance claimed it
+// before.
+func (s *clusterServer) Claim(ctx context.Context, in *botrpc.MessageClaim) (*botrpc.MessageClaim, error) {
+	if _, err := authenticate(ctx); err != nil {
+		return nil, err
+	}
+	in.Granted = claimLocal(in.Key)
+	return in, nil
+}
+
+// claimKey identifies in across integrations retrying it with different
+// instances. Messages without an id can't be told apart from new ones.
+func claimKey(in *botrpc.ChatMessage) (string, bool) {
+	if in.MessageId == "" {
+		return "", false
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "%q %q %q %q %q", in.Source, in.Channel, in.MessageId, in.User, in.Body)
+	if in.Reaction != nil {
+		fmt.Fprintf(h, " reaction %v %q", in.Reaction.Action, in.Reaction.Name)
+	}
+	if in.Event != nil {
+		fmt.Fprintf(h, " event %v %q", in.Event.Type, in.Event.Text)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// claim reports whether this instance may handle in. Every message is owned
+// by one instance picked by hashing its key, which grants only the first
+// claim. When the owner is down the next instance takes over, and if no
+// instance can be reached the message is handled.
+func claim(in *botrpc.ChatMessage) bool {
+	if !clustered() {
+		return true
+	}
+	key, ok := claimKey(in)
+	if !ok {
+		return true
+	}
+	return claimKeyed(key)
+}
+
+// claimKeyed reports whether this instance may handle what key identifies,
+// like claim does for messages.
+func claimKeyed(key string) bool {
+	if !clustered() {
+		return true
+	}
+	members := append([]string{config.clusterAddr}, config.clusterPeers...)
+	sort.Strings(members)
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	start := int(h.Sum32() % uint32(len(members)))
+	for i := range members {
+		owner := members[(start+i)%len(members)]
+		if owner == config.clusterAddr {
+			return claimLocal(key)
+		}
+		if !peerUp(owner) {
+			continue
+		}
+		granted, err := claimPeer(owner, key)
+		if err != nil {
+			log.Printf("error claiming message with %v: %v", owner, err)
+			continue
+		}
+		return granted
+	}
+	return true
+}
+
+func claimPeer(addr, key string) (bool, error) {
+	c, err := peerClient(addr)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	r, err := c.Claim(ctx, &botrpc.MessageClaim{Key: key, Instance: config.clusterAddr})
+	if err != nil {
+		return false, err
+	}
+	return r.Granted, nil
+}
+
+// Deliver sends in to the integrations subscribed to this instance.
+func (s *clusterServer) Deliver(ctx context.Context, in *botrpc.ChatMessage) (*botrpc.FuncStatus, error) {
+	if _, err := authenticate(ctx); err != nil {
+		return &botrpc.FuncStatus{Status: botrpc.FuncStatus_ERROR}, err
+	}
+	if err := deliverLocal(in); err != nil {
+		return &botrpc.FuncStatus{Status: botrpc.FuncStatus_ERROR}, err
+	}
+	return &botrpc.FuncStatus{Status: botrpc.FuncStatus_OK}, nil
+}
+
+// deliverPeers sends m to the first instance that has its integration
+// subscribed.
+func deliverPeers(m *botrpc.ChatMessage) error {
+	var errs []string
+	for _, addr := range config.clusterPeers {
+		if !peerUp(addr) {
+			continue
+		}
+		c, err := peerClient(addr)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err = c.Deliver(ctx, m)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%v: %v", addr, err))
+	}
+	return fmt.Errorf("no instance could deliver the message: %v", strings.Join(errs, "; "))
+}
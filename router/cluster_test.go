@@ -0,0 +1,27 @@
+package router
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+func TestStorageRefusesPeerTokens(t *testing.T) {
+	defer reset(t)()
+	applyEntry(&botrpc.RegistryEntry{
+		Key:      "deploybot/a:1/status",
+		Version:  1,
+		Instance: "b:8173",
+		Bot:      &botrpc.BotInfo{Name: "deploybot", Funcs: []*botrpc.Func{testFunc("a:1", "status", "!status")}},
+		Token:    "peer-token",
+	})
+	s := &storageServer{store: store}
+	_, err := s.Set(context.Background(), &botrpc.StorageItem{Token: "peer-token", Key: "k", Value: []byte("v")})
+	if grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Set with a token of another instance = %v, want FailedPrecondition", err)
+	}
+}
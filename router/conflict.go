@@ -0,0 +1,89 @@
+package router
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// conflict policies for CHATBOT_CONFLICT_POLICY.
+const (
+	conflictWarn   = "warn"   // add the func and return the conflicts as warnings
+	conflictReject = "reject" // refuse to add a func that conflicts
+)
+
+// checkConflicts returns the conflicts of cf with others as warnings, or an
+// error if the conflict policy rejects them.
+func checkConflicts(cf chatfunc, others []chatfunc) ([]string, error) {
+	warnings := conflicts(cf, others)
+	if len(warnings) > 0 && config.conflictPolicy == conflictReject {
+		return warnings, grpc.Errorf(codes.AlreadyExists, "func %v conflicts: %v", cf.FuncName, strings.Join(warnings, "; "))
+	}
+	for _, w := range warnings {
+		log.Printf("warning adding %v: %v", cf.FuncName, w)
+	}
+	return warnings, nil
+}
+
+// conflicts describes how cf overlaps with each of the funcs in others.
+func conflicts(cf chatfunc, others []chatfunc) []string {
+	var c []string
+	for _, o := range others {
+		c = append(c, conflictsWith(cf, o)...)
+	}
+	return c
+}
+
+// conflictsWith describes how a and b overlap. Besides identical names and
+// triggers the examples each func declares are checked against the other.
+func conflictsWith(a, b chatfunc) []string {
+	if a.bot != "" && a.replicaKey() == b.replicaKey() && a.Addr != b.Addr {
+		// replicas are meant to overlap.
+		return nil
+	}
+	var c []string
+	name := fmt.Sprintf("%v (%v) and %v (%v)", a.FuncName, a.Addr, b.FuncName, b.Addr)
+	switch {
+	case a.FuncName == b.FuncName && a.Addr == b.Addr:
+		c = append(c, fmt.Sprintf("%v are the same func", name))
+	case a.FuncName == b.FuncName && a.bot == b.bot:
+		// funcs of different bots are told apart by their qualified names.
+		c = append(c, fmt.Sprintf("%v have the same name", name))
+	}
+	if a.Trigger != "" && a.Trigger == b.Trigger && a.TriggerType == b.TriggerType && a.IgnoreCase == b.IgnoreCase {
+		c = append(c, fmt.Sprintf("%v have the same trigger %q", name, a.Trigger))
+	}
+	if a.ReactionTrigger != "" && a.ReactionTrigger == b.ReactionTrigger {
+		c = append(c, fmt.Sprintf("%v are both triggered by :%v:", name, a.ReactionTrigger))
+	}
+	for _, e := range a.Examples {
+		if b.triggered(&botrpc.ChatMessage{Body: e}) {
+			c = append(c, fmt.Sprintf("%v are both triggered by %q", name, e))
+		}
+	}
+	for _, e := range b.Examples {
+		if a.triggered(&botrpc.ChatMessage{Body: e}) {
+			c = append(c, fmt.Sprintf("%v are both triggered by %q", name, e))
+		}
+	}
+	return c
+}
+
+// conflictReport lists the conflicts between all the funcs that are added.
+func conflictReport(in *botrpc.ChatMessage, args []string, outStream botrpc.Bot_SendMessageServer) {
+	fs := funcs()
+	var c []string
+	for i := range fs {
+		c = append(c, conflicts(fs[i], fs[i+1:])...)
+	}
+	if len(c) == 0 {
+		sendNotice(in, outStream, "no conflicts between funcs.")
+		return
+	}
+	sendNotice(in, outStream, fmt.Sprintf("%d conflicts between funcs:\n%v", len(c), strings.Join(c, "\n")))
+}
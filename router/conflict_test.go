@@ -0,0 +1,105 @@
+package router
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// reset starts a test with an empty router and returns a func that cleans up.
+func reset(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "router")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Reset(filepath.Join(dir, "chatbot-storage.json")); err != nil {
+		t.Fatal(err)
+	}
+	return func() { os.RemoveAll(dir) }
+}
+
+func testFunc(addr, name, trigger string) *botrpc.Func {
+	return &botrpc.Func{
+		Addr:            addr,
+		FuncName:        name,
+		Trigger:         trigger,
+		TriggerType:     botrpc.Func_COMMAND,
+		ProtocolVersion: botrpc.ProtocolVersion,
+	}
+}
+
+func testChatfunc(t *testing.T, bot string, f *botrpc.Func) chatfunc {
+	cf, err := newChatfunc(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf.bot = bot
+	return cf
+}
+
+func TestConflictsWith(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      chatfunc
+		conflicts int
+	}{
+		{"same name in different bots",
+			testChatfunc(t, "deploybot", testFunc("a:1", "status", "!deploy-status")),
+			testChatfunc(t, "dbbot", testFunc("b:1", "status", "!db-status")),
+			0},
+		{"same name in the same bot",
+			testChatfunc(t, "deploybot", testFunc("a:1", "status", "!deploy-status")),
+			testChatfunc(t, "deploybot", testFunc("a:1", "status", "!status")),
+			1},
+		{"same name without bots",
+			testChatfunc(t, "", testFunc("a:1", "status", "!deploy-status")),
+			testChatfunc(t, "", testFunc("b:1", "status", "!db-status")),
+			1},
+		{"replicas",
+			testChatfunc(t, "deploybot", testFunc("a:1", "status", "!status")),
+			testChatfunc(t, "deploybot", testFunc("a:2", "status", "!status")),
+			0},
+		{"same trigger in different bots",
+			testChatfunc(t, "deploybot", testFunc("a:1", "status", "!status")),
+			testChatfunc(t, "dbbot", testFunc("b:1", "health", "!status")),
+			1},
+	}
+	for _, tt := range tests {
+		if c := conflictsWith(tt.a, tt.b); len(c) != tt.conflicts {
+			t.Errorf("%v: conflictsWith = %q, want %d conflicts", tt.name, c, tt.conflicts)
+		}
+	}
+}
+
+func TestRegisterBotReject(t *testing.T) {
+	defer reset(t)()
+	config.conflictPolicy = conflictReject
+	s := &server{}
+	ctx := context.Background()
+
+	st, err := s.RegisterBot(ctx, &botrpc.BotInfo{Name: "deploybot", Funcs: []*botrpc.Func{testFunc("a:1", "status", "!deploy-status")}})
+	if err != nil || st.Err() != nil {
+		t.Fatalf("RegisterBot(deploybot) = %v, %v", st, err)
+	}
+	// the same func name in another bot is qualified by the bot name.
+	st, err = s.RegisterBot(ctx, &botrpc.BotInfo{Name: "dbbot", Funcs: []*botrpc.Func{testFunc("b:1", "status", "!db-status")}})
+	if err != nil || st.Err() != nil {
+		t.Fatalf("RegisterBot(dbbot) = %v, %v", st, err)
+	}
+
+	st, err = s.RegisterBot(ctx, &botrpc.BotInfo{Name: "otherbot", Funcs: []*botrpc.Func{testFunc("c:1", "other", "!db-status")}})
+	if err != nil {
+		t.Fatalf("RegisterBot(otherbot) error = %v, want the rejection in the status", err)
+	}
+	if st.Status != botrpc.FuncStatus_ERROR || len(st.Funcs) != 1 || len(st.Funcs[0].Warnings) == 0 {
+		t.Errorf("RegisterBot(otherbot) = %v, want ERROR with warnings", st)
+	}
+	if len(funcs()) != 2 {
+		t.Errorf("%d funcs added, want 2", len(funcs()))
+	}
+}
@@ -0,0 +1,214 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// connectAddr is the Func.Addr given to the funcs of the nth bot connected
+// with Connect. It can't be dialed but identifies the replica like an address
+// does.
+func connectAddr(bot string, n uint64) string {
+	return fmt.Sprintf("connect:%v/%d", bot, n)
+}
+
+// botConn is a bot connected with Connect.
+type botConn struct {
+	stream botrpc.Bot_ConnectServer
+	sendMu sync.Mutex // guards stream.Send
+	cancel func()     // closes the stream
+
+	mu     sync.Mutex
+	next   uint64
+	calls  map[uint64]*pendingCall
+	closed bool // receive returned, no responses arrive anymore
+}
+
+// pendingCall receives the responses for a call until done is closed.
+type pendingCall struct {
+	ch   chan *botrpc.ConnectMessage
+	done chan struct{}
+}
+
+// connections contains the connected bots by their connectAddr.
+var connections = struct {
+	sync.Mutex
+	n uint64
+	m map[string]*botConn
+}{m: make(map[string]*botConn)}
+
+// connectedBot returns the connected bot for the func address addr.
+func connectedBot(addr string) (*botConn, bool) {
+	connections.Lock()
+	defer connections.Unlock()
+	c, ok := connections.m[addr]
+	return c, ok
+}
+
+// disconnect closes the stream of the bot connected at the func address addr,
+// if there is one.
+func disconnect(addr string) {
+	connections.Lock()
+	c, ok := connections.m[addr]
+	delete(connections.m, addr)
+	connections.Unlock()
+	if ok {
+		c.cancel()
+	}
+}
+
+// Connect registers the bot described in the first message and calls its
+// funcs over the stream until it closes or the bot is removed. Every
+// connection is a replica of the bot.
+func (s *server) Connect(stream botrpc.Bot_ConnectServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	bot := first.Bot
+	if bot == nil || bot.Name == "" {
+		return grpc.Errorf(codes.InvalidArgument, "the first message needs a bot with a name")
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	c := &botConn{stream: stream, cancel: cancel, calls: make(map[uint64]*pendingCall)}
+	connections.Lock()
+	connections.n++
+	addr := connectAddr(bot.Name, connections.n)
+	connections.m[addr] = c
+	connections.Unlock()
+	defer func() {
+		connections.Lock()
+		delete(connections.m, addr)
+		connections.Unlock()
+		removeReplica(bot.Name, addr)
+	}()
+	for _, f := range bot.Funcs {
+		f.Addr = addr
+	}
+
+	st, err := s.RegisterBot(stream.Context(), bot)
+	if err != nil {
+		return err
+	}
+	if err := c.send(&botrpc.ConnectMessage{Status: st}); err != nil {
+		return err
+	}
+	if st.Status != botrpc.FuncStatus_OK {
+		// the bot reads why from the status.
+		return nil
+	}
+	log.Printf("bot %v connected", bot.Name)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.receive()
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		// returning closes the stream, which ends receive.
+		return grpc.Errorf(codes.Aborted, "bot %v was removed", bot.Name)
+	}
+}
+
+func (c *botConn) send(m *botrpc.ConnectMessage) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.stream.Send(m)
+}
+
+// receive hands the responses from the bot to the calls they belong to until
+// the stream closes. The pending calls then fail.
+func (c *botConn) receive() error {
+	defer func() {
+		c.mu.Lock()
+		c.closed = true
+		for id, pc := range c.calls {
+			close(pc.ch)
+			delete(c.calls, id)
+		}
+		c.mu.Unlock()
+	}()
+	for {
+		m, err := c.stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		pc, ok := c.calls[m.CallId]
+		c.mu.Unlock()
+		if !ok {
+			continue
+		}
+		select {
+		case pc.ch <- m:
+		case <-pc.done:
+		}
+	}
+}
+
+// call sends in to the func cf on the bot and hands every response to send,
+// like callFunc does for bots it can dial. It gives up when ctx is done.
+func (c *botConn) call(ctx context.Context, cf chatfunc, in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) error {
+	pc := &pendingCall{ch: make(chan *botrpc.ConnectMessage, 16), done: make(chan struct{})}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("connected bot went away")
+	}
+	c.next++
+	id := c.next
+	c.calls[id] = pc
+	c.mu.Unlock()
+	defer func() {
+		close(pc.done)
+		c.mu.Lock()
+		delete(c.calls, id)
+		c.mu.Unlock()
+	}()
+
+	in.FuncName = cf.FuncName
+	if err := c.send(&botrpc.ConnectMessage{CallId: id, Message: adaptForFunc(cf, in)}); err != nil {
+		return fmt.Errorf("calling connected bot: %v", err)
+	}
+	for {
+		var m *botrpc.ConnectMessage
+		var ok bool
+		select {
+		case m, ok = <-pc.ch:
+		case <-ctx.Done():
+			return fmt.Errorf("calling connected bot: %v", ctx.Err())
+		}
+		if !ok {
+			return fmt.Errorf("connected bot went away")
+		}
+		if m.End {
+			if m.Error != "" {
+				return fmt.Errorf("connected bot: %v", m.Error)
+			}
+			return nil
+		}
+		if m.Message == nil {
+			continue
+		}
+		if err := send(m.Message); err == io.EOF {
+			return nil
+		} else if err != nil {
+			log.Printf("error streaming to integration: %v", err)
+			return nil
+		}
+	}
+}
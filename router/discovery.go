@@ -0,0 +1,169 @@
+package router
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// discovered are the manifests of the bots found by discovery by address.
+// Only runDiscovery uses it.
+var discovered = make(map[string]*botrpc.BotInfo)
+
+// runDiscovery adds the bots listed in the CHATBOT_BOTS_FILE and found with
+// the CHATBOT_BOTS_SRV records every interval. Bots that are no longer found
+// are removed. It never returns.
+func runDiscovery(interval time.Duration) {
+	for {
+		discover()
+		time.Sleep(interval)
+	}
+}
+
+// discover refreshes the discovered bots once.
+func discover() {
+	var addrs []string
+	if config.botsFile != "" {
+		a, err := readBotsFile(config.botsFile)
+		if err != nil {
+			log.Printf("error reading bots file: %v", err)
+			return
+		}
+		addrs = append(addrs, a...)
+	}
+	for _, name := range config.botsSRV {
+		a, err := lookupBots(name)
+		if err != nil {
+			// keep the bots found earlier, DNS failures are usually brief.
+			log.Printf("error looking up bots: %v", err)
+			return
+		}
+		addrs = append(addrs, a...)
+	}
+
+	found := make(map[string]bool)
+	for _, addr := range addrs {
+		found[addr] = true
+		bot, err := describe(addr)
+		if err != nil {
+			log.Printf("error describing bot at %v: %v", addr, err)
+			continue
+		}
+		// bots removed by an admin are only added again when they change.
+		if proto.Equal(bot, discovered[addr]) {
+			continue
+		}
+		st, err := (&server{}).RegisterBot(context.Background(), bot)
+		if err == nil {
+			err = st.Err()
+		}
+		if err != nil {
+			log.Printf("error adding bot at %v: %v", addr, err)
+			continue
+		}
+		removeDiscovered(discovered[addr], bot)
+		discovered[addr] = bot
+	}
+	for addr, bot := range discovered {
+		if !found[addr] {
+			removeDiscovered(bot, nil)
+			delete(discovered, addr)
+		}
+	}
+}
+
+// removeDiscovered removes the funcs old registered at addresses that bot,
+// the manifest replacing it, doesn't register funcs at. A nil bot removes
+// them all.
+func removeDiscovered(old, bot *botrpc.BotInfo) {
+	if old == nil {
+		return
+	}
+	keep := make(map[string]bool)
+	if bot != nil && bot.Name == old.Name {
+		for _, f := range bot.Funcs {
+			keep[f.Addr] = true
+		}
+	}
+	removed := make(map[string]bool)
+	for _, f := range old.Funcs {
+		if !keep[f.Addr] && !removed[f.Addr] {
+			removeReplica(old.Name, f.Addr)
+			removed[f.Addr] = true
+		}
+	}
+}
+
+// readBotsFile returns the bot addresses in the file at path, one per line.
+// Blank lines and lines starting with # are skipped.
+func readBotsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var addrs []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addrs = append(addrs, line)
+	}
+	return addrs, s.Err()
+}
+
+// lookupBots returns the bot addresses of the SRV records of name, e.g.
+// "_chatbot._tcp.example.com".
+func lookupBots(name string) ([]string, error) {
+	_, srvs, err := net.LookupSRV("", "", name)
+	if err != nil {
+		return nil, err
+	}
+	var addrs []string
+	for _, srv := range srvs {
+		host := strings.TrimSuffix(srv.Target, ".")
+		addrs = append(addrs, net.JoinHostPort(host, fmt.Sprint(srv.Port)))
+	}
+	return addrs, nil
+}
+
+// describe asks the bot at addr for its manifest. Funcs without an address
+// get addr and a bot without a name is named after addr.
+func describe(addr string) (*botrpc.BotInfo, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("connecting with bot: %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	bot, err := botrpc.NewBotFuncsClient(conn).Describe(ctx, &botrpc.DescribeRequest{
+		ProtocolVersion: botrpc.ProtocolVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if bot.Name == "" {
+		bot.Name = addr
+	}
+	for _, f := range bot.Funcs {
+		if f.Addr == "" {
+			f.Addr = addr
+		}
+	}
+	return bot, nil
+}
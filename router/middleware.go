@@ -0,0 +1,190 @@
+package router
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// Middleware can change or drop messages on their way from the integrations to
+// the bots and back. Returning a nil message drops it.
+type Middleware interface {
+	// Inbound is called with every message from an integration before the
+	// triggers are checked.
+	Inbound(in *botrpc.ChatMessage) (*botrpc.ChatMessage, error)
+	// Outbound is called with every response before it is sent to an
+	// integration. in is the message that was responded to, or nil if a bot
+	// posted out on its own.
+	Outbound(in, out *botrpc.ChatMessage) (*botrpc.ChatMessage, error)
+}
+
+// middlewareChain runs inbound messages through the middleware in order and
+// outbound messages in reverse order, so the first middleware sees messages
+// as they come from and go to the integrations.
+type middlewareChain []Middleware
+
+// middleware is the chain configured with CHATBOT_MIDDLEWARE.
+var middleware middlewareChain
+
+// inbound returns in after it went through the chain, or nil if it was
+// dropped. Errors drop the message so a failing filter can't be bypassed.
+func (c middlewareChain) inbound(in *botrpc.ChatMessage) *botrpc.ChatMessage {
+	for _, m := range c {
+		var err error
+		if in, err = m.Inbound(in); err != nil {
+			log.Printf("error in inbound middleware: %v", err)
+			return nil
+		}
+		if in == nil {
+			return nil
+		}
+	}
+	return in
+}
+
+// outbound returns out after it went through the chain, or nil if it was
+// dropped.
+func (c middlewareChain) outbound(in, out *botrpc.ChatMessage) *botrpc.ChatMessage {
+	for i := len(c) - 1; i >= 0; i-- {
+		var err error
+		if out, err = c[i].Outbound(in, out); err != nil {
+			log.Printf("error in outbound middleware: %v", err)
+			return nil
+		}
+		if out == nil {
+			return nil
+		}
+	}
+	return out
+}
+
+// middlewares are the built in middleware by name.
+var middlewares = map[string]func() Middleware{
+	"normalize": func() Middleware { return normalize{} },
+	"redact":    func() Middleware { return redact{} },
+}
+
+// parseMiddleware creates a chain from a comma separated list of built in
+// middleware names and remote middleware given as remote=addr.
+func parseMiddleware(spec string) (middlewareChain, error) {
+	var c middlewareChain
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case strings.HasPrefix(name, "remote="):
+			m, err := newRemoteMiddleware(strings.TrimPrefix(name, "remote="))
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, m)
+		default:
+			newM, ok := middlewares[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown middleware %q", name)
+			}
+			c = append(c, newM())
+		}
+	}
+	return c, nil
+}
+
+// normalize trims whitespace and line endings of inbound messages so triggers
+// don't have to deal with them.
+type normalize struct{}
+
+func (normalize) Inbound(in *botrpc.ChatMessage) (*botrpc.ChatMessage, error) {
+	in.Body = strings.TrimSpace(strings.Replace(in.Body, "\r\n", "\n", -1))
+	return in, nil
+}
+
+func (normalize) Outbound(in, out *botrpc.ChatMessage) (*botrpc.ChatMessage, error) {
+	return out, nil
+}
+
+// secretExprs match secrets that shouldn't be sent to bots or posted in chat.
+var secretExprs = []*regexp.Regexp{
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`xox[abprs]-[0-9A-Za-z-]+`),
+	regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`(?i)\b(password|passwd|secret|api_?key|token)(\s*[:=]\s*)\S+`),
+}
+
+// redact replaces secrets in messages in both directions.
+type redact struct{}
+
+func redactText(s string) string {
+	for _, re := range secretExprs {
+		if re.NumSubexp() == 2 {
+			s = re.ReplaceAllString(s, "${1}${2}[redacted]")
+			continue
+		}
+		s = re.ReplaceAllString(s, "[redacted]")
+	}
+	return s
+}
+
+func (redact) Inbound(in *botrpc.ChatMessage) (*botrpc.ChatMessage, error) {
+	in.Body = redactText(in.Body)
+	return in, nil
+}
+
+func (redact) Outbound(in, out *botrpc.ChatMessage) (*botrpc.ChatMessage, error) {
+	out.Body = redactText(out.Body)
+	for _, a := range out.Attachments {
+		a.Text = redactText(a.Text)
+		a.Code = redactText(a.Code)
+		for _, f := range a.Fields {
+			f.Value = redactText(f.Value)
+		}
+	}
+	return out, nil
+}
+
+// remoteMiddleware calls a botrpc.MiddlewareServer.
+type remoteMiddleware struct {
+	addr   string
+	client botrpc.MiddlewareClient
+}
+
+func newRemoteMiddleware(addr string) (*remoteMiddleware, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("connecting with middleware %v: %v", addr, err)
+	}
+	return &remoteMiddleware{addr: addr, client: botrpc.NewMiddlewareClient(conn)}, nil
+}
+
+func (r *remoteMiddleware) process(in *botrpc.MiddlewareMessage) (*botrpc.ChatMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := r.client.Process(ctx, in)
+	if err != nil {
+		return nil, fmt.Errorf("middleware %v: %v", r.addr, err)
+	}
+	return out.Message, nil
+}
+
+func (r *remoteMiddleware) Inbound(in *botrpc.ChatMessage) (*botrpc.ChatMessage, error) {
+	return r.process(&botrpc.MiddlewareMessage{
+		Direction: botrpc.MiddlewareMessage_INBOUND,
+		Message:   in,
+	})
+}
+
+func (r *remoteMiddleware) Outbound(in, out *botrpc.ChatMessage) (*botrpc.ChatMessage, error) {
+	return r.process(&botrpc.MiddlewareMessage{
+		Direction: botrpc.MiddlewareMessage_OUTBOUND,
+		Message:   out,
+		Request:   in,
+	})
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// qualifiedName is the name of cf including the bot it belongs to, e.g.
+// "deploybot.status". Funcs added without a bot only have their own name.
+func (cf chatfunc) qualifiedName() string {
+	if cf.bot == "" {
+		return cf.FuncName
+	}
+	return cf.bot + "." + cf.FuncName
+}
+
+// qualifiedPools returns the pool of the func a command like
+// "!deploybot.status prod" names and the message to call it with, which has
+// the bot name removed. It reports false if in doesn't name a func. Bot names
+// can contain dots, like the addresses discovered bots are named after, so
+// the func name follows the last one.
+func qualifiedPools(in *botrpc.ChatMessage) ([][]chatfunc, *botrpc.ChatMessage, bool) {
+	if !addressed(in) {
+		return nil, nil, false
+	}
+	word := firstWord(strings.TrimPrefix(in.Body, config.commandPrefix))
+	i := strings.LastIndex(word, ".")
+	if i <= 0 {
+		return nil, nil, false
+	}
+	bot, name := word[:i], word[i+1:]
+	var fs []chatfunc
+	for _, cf := range funcs() {
+		if cf.bot == bot && cf.FuncName == name {
+			fs = append(fs, cf)
+		}
+	}
+	if len(fs) == 0 {
+		return nil, nil, false
+	}
+	qin := *in
+	qin.Body = strings.Replace(in.Body, word, name, 1)
+	return pools(fs), &qin, true
+}
+
+// matchPools returns the pools of the funcs in should be sent to and the
+// message to send them, which differs from in for qualified commands.
+func matchPools(in *botrpc.ChatMessage) ([][]chatfunc, *botrpc.ChatMessage) {
+	if ps, qin, ok := qualifiedPools(in); ok {
+		return ps, qin
+	}
+	return triggeredPools(in), in
+}
+
+// ambiguous returns the pools that share their func name with a pool of
+// another bot, by func name. Funcs added without a bot can't be qualified, so
+// they are never ambiguous and are always called like before namespacing.
+func ambiguous(ps [][]chatfunc) map[string][][]chatfunc {
+	byName := make(map[string][][]chatfunc)
+	for _, pool := range ps {
+		if pool[0].bot == "" {
+			continue
+		}
+		byName[pool[0].FuncName] = append(byName[pool[0].FuncName], pool)
+	}
+	for name, pools := range byName {
+		if len(pools) < 2 {
+			delete(byName, name)
+		}
+	}
+	return byName
+}
+
+// dropAmbiguous removes the pools of funcs with the same name in different
+// bots from ps and tells the user how to call the one they meant. Only
+// commands can be ambiguous, every pool is called for the other messages like
+// events, reactions and text the bot wasn't addressed with.
+func dropAmbiguous(in *botrpc.ChatMessage, ps [][]chatfunc, outStream botrpc.Bot_SendMessageServer) [][]chatfunc {
+	if !addressed(in) {
+		return ps
+	}
+	amb := ambiguous(ps)
+	if len(amb) == 0 {
+		return ps
+	}
+	var names []string
+	for name := range amb {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var q []string
+		for _, pool := range amb[name] {
+			q = append(q, config.commandPrefix+pool[0].qualifiedName())
+		}
+		sendNotice(in, outStream, fmt.Sprintf("sorry, %v is ambiguous. Use %v.", name, strings.Join(q, " or ")))
+	}
+	var keep [][]chatfunc
+	for _, pool := range ps {
+		if _, ok := amb[pool[0].FuncName]; !ok || pool[0].bot == "" {
+			keep = append(keep, pool)
+		}
+	}
+	return keep
+}
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+func TestDropAmbiguous(t *testing.T) {
+	welcome := func(addr string) *botrpc.Func {
+		return &botrpc.Func{
+			Addr:            addr,
+			FuncName:        "welcome",
+			Events:          []botrpc.ChatEvent_Type{botrpc.ChatEvent_MEMBER_JOINED},
+			Capabilities:    []botrpc.Capability{botrpc.Capability_EVENTS},
+			ProtocolVersion: botrpc.ProtocolVersion,
+		}
+	}
+	status := func(addr string) *botrpc.Func {
+		f := testFunc(addr, "status", "!status")
+		f.TriggerType = botrpc.Func_REGEX
+		return f
+	}
+	ps := [][]chatfunc{
+		{testChatfunc(t, "greetbot", welcome("a:1"))},
+		{testChatfunc(t, "hibot", welcome("b:1"))},
+	}
+	joined := &botrpc.ChatMessage{Event: &botrpc.ChatEvent{Type: botrpc.ChatEvent_MEMBER_JOINED}}
+	if got := dropAmbiguous(joined, ps, nil); len(got) != 2 {
+		t.Errorf("dropAmbiguous(event) kept %d pools, want 2", len(got))
+	}
+
+	ps = [][]chatfunc{
+		{testChatfunc(t, "", status("a:1"))},
+		{testChatfunc(t, "", status("b:1"))},
+	}
+	if got := dropAmbiguous(&botrpc.ChatMessage{Body: "!status"}, ps, nil); len(got) != 2 {
+		t.Errorf("dropAmbiguous(funcs without bots) kept %d pools, want 2", len(got))
+	}
+}
+
+func TestQualifiedPools(t *testing.T) {
+	defer reset(t)()
+	s := &server{}
+	for _, name := range []string{"deploybot", "10.0.0.1:8081"} {
+		st, err := s.RegisterBot(context.Background(), &botrpc.BotInfo{Name: name, Funcs: []*botrpc.Func{testFunc(name, "hello", "!hello")}})
+		if err != nil || st.Err() != nil {
+			t.Fatalf("RegisterBot(%v) = %v, %v", name, st, err)
+		}
+	}
+	tests := []struct {
+		body, bot, qbody string
+	}{
+		{"!deploybot.hello there", "deploybot", "!hello there"},
+		{"!10.0.0.1:8081.hello", "10.0.0.1:8081", "!hello"},
+		{"!nobot.hello", "", ""},
+		{"!hello", "", ""},
+	}
+	for _, tt := range tests {
+		ps, qin, ok := qualifiedPools(&botrpc.ChatMessage{Body: tt.body})
+		if tt.bot == "" {
+			if ok {
+				t.Errorf("qualifiedPools(%q) found %v, want none", tt.body, ps)
+			}
+			continue
+		}
+		if !ok || len(ps) != 1 || ps[0][0].bot != tt.bot || qin.Body != tt.qbody {
+			t.Errorf("qualifiedPools(%q) = %v, %v, %v, want %v with %q", tt.body, ps, qin, ok, tt.bot, tt.qbody)
+		}
+	}
+}
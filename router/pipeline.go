@@ -0,0 +1,96 @@
+package router
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// maxPipelineStages limits how many funcs can be chained in one message.
+const maxPipelineStages = 5
+
+// stage is one command of a pipeline with the pools of funcs it triggers.
+type stage struct {
+	body  string
+	pools [][]chatfunc
+}
+
+// parsePipeline splits a message like "!grep-logs api | !summarize" into its
+// stages. Messages are only pipelines if every stage is a command starting
+// with the command prefix and triggers a func, so a | in normal text doesn't
+// change how the message is handled.
+func parsePipeline(in *botrpc.ChatMessage) ([]stage, bool) {
+	if in.Reaction != nil || in.Event != nil || !strings.Contains(in.Body, "|") {
+		return nil, false
+	}
+	parts := strings.Split(in.Body, "|")
+	stages := make([]stage, 0, len(parts))
+	for _, p := range parts {
+		m := *in
+		m.Body = strings.TrimSpace(p)
+		if !strings.HasPrefix(m.Body, config.commandPrefix) {
+			return nil, false
+		}
+		ps, sin := matchPools(&m)
+		st := stage{body: sin.Body, pools: ps}
+		if len(st.pools) == 0 {
+			return nil, false
+		}
+		stages = append(stages, st)
+	}
+	return stages, true
+}
+
+// handlePipeline calls the funcs of the stages in order. The output of each
+// stage is the input of the next one and the responses of the last stage are
+// sent back on outStream.
+func handlePipeline(in *botrpc.ChatMessage, stages []stage, outStream botrpc.Bot_SendMessageServer) error {
+	if len(stages) > maxPipelineStages {
+		sendNotice(in, outStream, fmt.Sprintf("sorry, pipelines can't have more than %d stages.", maxPipelineStages))
+		return nil
+	}
+	start := time.Now()
+	var called []string
+	var errs []error
+	defer func() { recordAudit(in, called, start, errs) }()
+	var input string
+	for i, st := range stages {
+		if len(st.pools) > 1 {
+			sendNotice(in, outStream, fmt.Sprintf("sorry, %q triggers more than one func and can't be used in a pipeline.", st.body))
+			return nil
+		}
+		pool := st.pools[0]
+		last := i == len(stages)-1
+		called = append(called, pool[0].qualifiedName())
+
+		sin := *in
+		sin.Body, sin.Input = st.body, input
+		var outputs []string
+		err := callPool(pool, &sin, func(cf chatfunc, out *botrpc.ChatMessage) error {
+			if last {
+				return respond(in, out, outStream)
+			}
+			if out.Update && len(outputs) > 0 {
+				outputs[len(outputs)-1] = out.PlainText()
+				return nil
+			}
+			outputs = append(outputs, out.PlainText())
+			return nil
+		})
+		if err != nil {
+			log.Printf("error calling %v in pipeline: %v", pool[0].FuncName, err)
+			sendError(in, outStream, pool[0].FuncName)
+			errs = append(errs, fmt.Errorf("%v: %v", pool[0].qualifiedName(), err))
+			return nil
+		}
+		if !last && len(outputs) == 0 {
+			sendNotice(in, outStream, fmt.Sprintf("sorry, %v had no output for the rest of the pipeline.", pool[0].FuncName))
+			return nil
+		}
+		input = strings.Join(outputs, "\n")
+	}
+	return nil
+}
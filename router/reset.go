@@ -0,0 +1,60 @@
+package router
+
+import (
+	"time"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// Reset drops all the funcs, bots, integrations, sessions and cluster state
+// of the router and opens the storage in storageFile. It sets config to the
+// defaults and turns the audit log and transcript off. It is meant for tests
+// running a router in process, see package chatbottest. Since the state is
+// global, only one such router can run at a time.
+func Reset(storageFile string) error {
+	s, err := openStore(storageFile)
+	if err != nil {
+		return err
+	}
+
+	chatFuncsMu.Lock()
+	chatFuncs = nil
+	bots = make(map[string]*botrpc.BotInfo)
+	discovered = make(map[string]*botrpc.BotInfo)
+	chatFuncsMu.Unlock()
+
+	config = defaults()
+	config.storageFile = storageFile
+	config.auditFile = "off"
+	store = s
+	audit = nil
+	transcript = nil
+	middleware = nil
+
+	tokens.Lock()
+	tokens.m = make(map[string]tokenInfo)
+	tokens.Unlock()
+	integrations.Lock()
+	integrations.m = make(map[string]*botrpc.Integration)
+	integrations.Unlock()
+	subscribers.Lock()
+	subscribers.m = make(map[*subscriber]bool)
+	subscribers.Unlock()
+	sessions.Lock()
+	sessions.m = make(map[sessionKey]*session)
+	sessions.Unlock()
+	balancer.Lock()
+	balancer.next = make(map[string]int)
+	balancer.inFlight = make(map[string]int)
+	balancer.Unlock()
+	connections.Lock()
+	connections.m = make(map[string]*botConn)
+	connections.Unlock()
+	registry.Lock()
+	registry.m = make(map[string]*botrpc.RegistryEntry)
+	registry.Unlock()
+	claims.Lock()
+	claims.m = make(map[string]time.Time)
+	claims.Unlock()
+	return nil
+}
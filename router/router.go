@@ -0,0 +1,608 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// server is used to implement the BotServer interface.
+type server struct{}
+
+// Add adds a function to the server. This should be called for each function
+// that a bot can respond. Adding a func again with the same addr and
+// func_name, as bots do when they restart, replaces it. A func rejected for
+// its conflicts gets the ERROR status with the conflicts as warnings.
+func (s *server) Add(ctx context.Context, in *botrpc.Func) (*botrpc.FuncStatus, error) {
+	cf, err := newChatfunc(in)
+	if err != nil {
+		return &botrpc.FuncStatus{
+			Status: 0,
+		}, err
+	}
+	replaced := func(f chatfunc) bool {
+		return f.bot == "" && f.Addr == cf.Addr && f.FuncName == cf.FuncName
+	}
+	chatFuncsMu.Lock()
+	defer chatFuncsMu.Unlock()
+	var others []chatfunc
+	for _, f := range chatFuncs {
+		if !replaced(f) {
+			others = append(others, f)
+		}
+	}
+	warnings, err := checkConflicts(cf, others)
+	if err != nil {
+		// the conflicts are only readable by the bot in a response without
+		// an error.
+		log.Printf("error adding %v: %v", cf.FuncName, err)
+		return &botrpc.FuncStatus{
+			Status:   0,
+			Warnings: warnings,
+		}, nil
+	}
+	if cf.token, err = newToken(cf); err != nil {
+		return &botrpc.FuncStatus{
+			Status: 0,
+		}, err
+	}
+	publishRemoved(removeFuncs(replaced))
+	chatFuncs = append(chatFuncs, cf)
+	publishAdded(cf)
+	return &botrpc.FuncStatus{
+		Status:          1,
+		Token:           cf.token,
+		Warnings:        warnings,
+		ProtocolVersion: botrpc.ProtocolVersion,
+	}, nil
+}
+
+// newChatfunc checks the func f and compiles its triggers and schedules.
+func newChatfunc(f *botrpc.Func) (chatfunc, error) {
+	if f.Trigger == "" && f.ReactionTrigger == "" && len(f.Events) == 0 && len(f.Schedules) == 0 {
+		return chatfunc{}, fmt.Errorf("func %v has no trigger", f.FuncName)
+	}
+	if err := checkFuncCapabilities(f); err != nil {
+		return chatfunc{}, err
+	}
+	cf := chatfunc{Func: *f}
+	if f.Trigger != "" {
+		m, err := compileTrigger(f)
+		if err != nil {
+			return chatfunc{}, err
+		}
+		cf.trigger = m
+	}
+	for _, sch := range f.Schedules {
+		s, err := newSchedule(sch)
+		if err != nil {
+			return chatfunc{}, err
+		}
+		cf.schedules = append(cf.schedules, s)
+	}
+	return cf, nil
+}
+
+// Remove deletes the func from server so it will no longer trigger. Bots
+// should call it for their funcs when they shut down.
+func (s *server) Remove(ctx context.Context, in *botrpc.Func) (*botrpc.FuncStatus, error) {
+	chatFuncsMu.Lock()
+	defer chatFuncsMu.Unlock()
+	removed := removeFuncs(func(cf chatfunc) bool {
+		return cf.Addr == in.Addr && cf.FuncName == in.FuncName
+	})
+	publishRemoved(removed)
+	if len(removed) == 0 {
+		return &botrpc.FuncStatus{
+			Status: 0,
+		}, grpc.Errorf(codes.NotFound, "func %v at %v not found", in.FuncName, in.Addr)
+	}
+	return &botrpc.FuncStatus{
+		Status: 1,
+	}, nil
+}
+
+// SendMessage recieves messages from the integrations and handles them.
+func (s *server) SendMessage(in *botrpc.ChatMessage, stream botrpc.Bot_SendMessageServer) error {
+	if transcript != nil {
+		return handleRecorded(in, stream)
+	}
+	return handleChat(in, stream)
+}
+
+// chatfunc is a botrpc.Func with the compiled trigger.
+type chatfunc struct {
+	botrpc.Func
+	trigger   matcher
+	schedules []schedule
+	bot       string // name of the bot for funcs added with RegisterBot
+	token     string
+}
+
+// triggered reports whether in should be sent to the func. Reactions only
+// trigger funcs with a matching ReactionTrigger and events only trigger funcs
+// subscribed to them.
+func (cf chatfunc) triggered(in *botrpc.ChatMessage) bool {
+	switch {
+	case in.Event != nil:
+		for _, t := range cf.Events {
+			if t == in.Event.Type {
+				return true
+			}
+		}
+		return false
+	case in.Reaction != nil:
+		return cf.ReactionTrigger != "" && cf.ReactionTrigger == in.Reaction.Name
+	}
+	return cf.trigger != nil && cf.trigger(in.Body)
+}
+
+// triggerHelp describes the triggers of the func for the help output.
+func (cf chatfunc) triggerHelp() string {
+	var t []string
+	if cf.Trigger != "" {
+		t = append(t, fmt.Sprintf("%s%q", triggerTypeHelp[cf.TriggerType], cf.Trigger))
+	}
+	if cf.ReactionTrigger != "" {
+		t = append(t, fmt.Sprintf(":%s:", cf.ReactionTrigger))
+	}
+	for _, e := range cf.Events {
+		t = append(t, "event:"+strings.ToLower(e.String()))
+	}
+	for _, s := range cf.schedules {
+		t = append(t, fmt.Sprintf("schedule:%q", s.Cron))
+	}
+	return strings.Join(t, " ")
+}
+
+// chatFuncs contains all the registered botrpc.Func with compiled triggers.
+// It is guarded by chatFuncsMu, use funcs to read it.
+var (
+	chatFuncs   []chatfunc
+	chatFuncsMu sync.RWMutex
+)
+
+// funcs returns a copy of chatFuncs that is safe to range over.
+func funcs() []chatfunc {
+	chatFuncsMu.RLock()
+	defer chatFuncsMu.RUnlock()
+	return append([]chatfunc(nil), chatFuncs...)
+}
+
+// removeFuncs removes the funcs that remove returns true for, revokes their
+// tokens, ends their sessions and disconnects the connected bots left without
+// funcs. It returns the removed funcs. The caller must hold chatFuncsMu.
+func removeFuncs(remove func(cf chatfunc) bool) []chatfunc {
+	var keep, removed []chatfunc
+	for _, cf := range chatFuncs {
+		if !remove(cf) {
+			keep = append(keep, cf)
+			continue
+		}
+		revokeToken(cf.token)
+		removed = append(removed, cf)
+	}
+	chatFuncs = keep
+	for _, cf := range removed {
+		if !hasAddr(keep, cf.Addr) {
+			disconnect(cf.Addr)
+		}
+	}
+	if len(removed) > 0 {
+		endSessions(keep)
+	}
+	return removed
+}
+
+// hasAddr reports whether any of fs is at addr.
+func hasAddr(fs []chatfunc, addr string) bool {
+	for _, f := range fs {
+		if f.Addr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// options are the settings of the router.
+type options struct {
+	addr           string
+	storageFile    string
+	commandPrefix  string
+	conflictPolicy string
+	admins         []string
+	botsFile       string
+	botsSRV        []string
+	discovery      time.Duration
+	balance        string
+	clusterAddr    string
+	clusterPeers   []string
+	clusterSecret  string
+	auditFile      string
+	auditMaxSize   int64
+	adminToken     string
+	transcriptFile string
+}
+
+// defaults returns the options used where the environment doesn't set any.
+func defaults() options {
+	return options{
+		addr:           "0.0.0.0:8173",
+		storageFile:    "chatbot-storage.json",
+		commandPrefix:  "!",
+		conflictPolicy: conflictWarn,
+		discovery:      time.Minute,
+		balance:        balanceRoundRobin,
+		auditFile:      "chatbot-audit.jsonl",
+		auditMaxSize:   100 << 20,
+	}
+}
+
+// config is a convenient group for global variables.
+var config = defaults()
+
+// callTimeout is how long a func may take to send all its responses.
+const callTimeout = time.Minute
+
+// store is the storage shared by the Storage service and the router itself.
+var store *fileStore
+
+var errorChan = make(chan error)
+
+// Main configures the router from the environment and runs it until it gets
+// SIGINT or SIGTERM.
+func Main() {
+	log.SetOutput(os.Stdout)
+	if addr := os.Getenv("CHATBOT_ADDR"); addr != "" {
+		config.addr = addr
+	}
+	if f := os.Getenv("CHATBOT_STORAGE_FILE"); f != "" {
+		config.storageFile = f
+	}
+	if p := os.Getenv("CHATBOT_COMMAND_PREFIX"); p != "" {
+		config.commandPrefix = p
+	}
+	if p := os.Getenv("CHATBOT_CONFLICT_POLICY"); p != "" {
+		if p != conflictWarn && p != conflictReject {
+			log.Fatalf("unknown conflict policy %q", p)
+		}
+		config.conflictPolicy = p
+	}
+	if a := os.Getenv("CHATBOT_ADMINS"); a != "" {
+		config.admins = strings.Split(a, ",")
+	}
+	if b := os.Getenv("CHATBOT_BALANCE"); b != "" {
+		if b != balanceRoundRobin && b != balanceLeastInFlight {
+			log.Fatalf("unknown balancing policy %q", b)
+		}
+		config.balance = b
+	}
+	if f := os.Getenv("CHATBOT_BOTS_FILE"); f != "" {
+		config.botsFile = f
+	}
+	if srv := os.Getenv("CHATBOT_BOTS_SRV"); srv != "" {
+		config.botsSRV = strings.Split(srv, ",")
+	}
+	if d := os.Getenv("CHATBOT_DISCOVERY_INTERVAL"); d != "" {
+		var err error
+		if config.discovery, err = time.ParseDuration(d); err != nil || config.discovery <= 0 {
+			log.Fatalf("invalid discovery interval %q", d)
+		}
+	}
+	if a := os.Getenv("CHATBOT_CLUSTER_ADDR"); a != "" {
+		config.clusterAddr = a
+	}
+	if p := os.Getenv("CHATBOT_CLUSTER_PEERS"); p != "" {
+		config.clusterPeers = strings.Split(p, ",")
+		if config.clusterAddr == "" {
+			log.Fatalf("CHATBOT_CLUSTER_ADDR is needed to run in a cluster")
+		}
+		if config.clusterSecret = os.Getenv("CHATBOT_CLUSTER_SECRET"); config.clusterSecret == "" {
+			log.Fatalf("CHATBOT_CLUSTER_SECRET is needed to run in a cluster")
+		}
+	}
+	if f := os.Getenv("CHATBOT_AUDIT_FILE"); f != "" {
+		config.auditFile = f
+	}
+	if n := os.Getenv("CHATBOT_AUDIT_MAX_SIZE"); n != "" {
+		var err error
+		if config.auditMaxSize, err = strconv.ParseInt(n, 10, 64); err != nil {
+			log.Fatalf("invalid audit max size %q", n)
+		}
+	}
+	config.adminToken = os.Getenv("CHATBOT_ADMIN_TOKEN")
+	config.transcriptFile = os.Getenv("CHATBOT_TRANSCRIPT_FILE")
+	if m := os.Getenv("CHATBOT_MIDDLEWARE"); m != "" {
+		var err error
+		if middleware, err = parseMiddleware(m); err != nil {
+			log.Fatalf("error configuring middleware: %v", err)
+		}
+	}
+
+	var err error
+	if store, err = openStore(config.storageFile); err != nil {
+		log.Fatalf("error opening storage: %v", err)
+	}
+	if config.transcriptFile != "" {
+		if transcript, err = openTranscript(config.transcriptFile); err != nil {
+			log.Fatalf("error opening transcript: %v", err)
+		}
+	}
+	// CHATBOT_AUDIT_FILE=off turns the audit log off.
+	if config.auditFile != "off" {
+		if audit, err = openAuditLog(config.auditFile, config.auditMaxSize); err != nil {
+			log.Fatalf("error opening audit log: %v", err)
+		}
+	}
+
+	// start registration server
+	go func() {
+		errorChan <- startBotServer()
+	}()
+
+	go runScheduler()
+	if config.botsFile != "" || len(config.botsSRV) > 0 {
+		go runDiscovery(config.discovery)
+	}
+	if clustered() {
+		log.Printf("running in a cluster: only the funcs are shared, storage, sessions and suggestion settings stay with each instance")
+		go runCluster()
+	}
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case e := <-errorChan:
+		log.Fatalf("error occurred: %v", e)
+	case s := <-signalChan:
+		log.Println(fmt.Sprintf("Captured %v. Exitting...", s))
+		// shutdown incoming chat listener
+		// shutdown registration server
+		os.Exit(0)
+	}
+}
+
+// startBotServer listens on the address specified in config.addr and handles
+// rpcs. Have to check if we can store the server for draining and closing the
+// connection.
+func startBotServer() error {
+	lis, err := net.Listen("tcp", config.addr)
+	if err != nil {
+		log.Printf("failed to listen: %v\n", err)
+	}
+	s := grpc.NewServer()
+	RegisterServers(s)
+	return s.Serve(lis)
+}
+
+// RegisterServers registers the services of the router with s. The Cluster
+// service is only served when the router runs in a cluster.
+func RegisterServers(s *grpc.Server) {
+	botrpc.RegisterBotServer(s, &server{})
+	botrpc.RegisterStorageServer(s, &storageServer{store: store})
+	if clustered() {
+		botrpc.RegisterClusterServer(s, &clusterServer{})
+	}
+	botrpc.RegisterAdminServer(s, &adminServer{})
+}
+
+// handleChat checks if any bots are triggered and sends all the responses back
+// on outStream.
+func handleChat(in *botrpc.ChatMessage, outStream botrpc.Bot_SendMessageServer) error {
+	if len(funcs()) == 0 {
+		return nil
+	}
+	// another instance of the cluster handles the message.
+	if !claim(in) {
+		return nil
+	}
+	if in = middleware.inbound(in); in == nil {
+		return nil
+	}
+
+	// messages from a user in a session skip the triggers.
+	if cf, ok := activeSession(in); ok {
+		return handleSession(cf, in, outStream)
+	}
+
+	// TODO: handle help
+	if in.Reaction == nil && in.Event == nil && strings.ToLower(in.Body) == "help" {
+		var buf bytes.Buffer
+		w := tabwriter.NewWriter(&buf, 0, 8, 0, '\t', 0)
+		fmt.Fprintf(w, "func\ttrigger\thelp\n")
+		// replicas are shown once.
+		var fs []chatfunc
+		for _, pool := range pools(funcs()) {
+			fs = append(fs, pool[0])
+		}
+		for _, cf := range fs {
+			if cf.bot == "" {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", cf.qualifiedName(), cf.triggerHelp(), cf.Usage)
+			}
+		}
+		for _, b := range registeredBots() {
+			fmt.Fprintf(w, "\n%s\t\t%s\n", b.Name, b.Description)
+			for _, cf := range fs {
+				if cf.bot == b.Name {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", cf.qualifiedName(), cf.triggerHelp(), cf.Usage)
+				}
+			}
+		}
+		w.Flush()
+		cm := &botrpc.ChatMessage{
+			Body:       buf.String(),
+			Channel:    in.Channel,
+			Visibility: botrpc.ChatMessage_EPHEMERAL,
+		}
+		return respond(in, cm, outStream)
+	}
+
+	if stages, ok := parsePipeline(in); ok {
+		return handlePipeline(in, stages, outStream)
+	}
+
+	if toggleSuggestions(in, outStream) || handleAdmin(in, outStream) {
+		return nil
+	}
+
+	// call one replica of each triggered func
+	ps, fin := matchPools(in)
+	if len(ps) == 0 {
+		if addressed(in) && suggestionsOn(in) {
+			suggest(in, outStream)
+		}
+		return nil
+	}
+	start := time.Now()
+	var called []string
+	var errs []error
+	for _, pool := range dropAmbiguous(in, ps, outStream) {
+		called = append(called, pool[0].qualifiedName())
+		err := callPool(pool, fin, func(cf chatfunc, out *botrpc.ChatMessage) error {
+			// send it to integration
+			updateSession(in, cf, out.Session)
+			return respond(in, out, outStream)
+		})
+		if err != nil {
+			log.Printf("error calling %v: %v", pool[0].FuncName, err)
+			sendError(in, outStream, pool[0].FuncName)
+			errs = append(errs, fmt.Errorf("%v: %v", pool[0].qualifiedName(), err))
+		}
+	}
+	recordAudit(in, called, start, errs)
+	return nil
+}
+
+// callFunc sends in to the bot func cf and hands every response to send. An
+// error is returned when the bot can't be reached or fails while streaming.
+// Bots connected with Connect are called over their stream. Updates are held
+// back for integrations that can't show them.
+func callFunc(cf chatfunc, in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) error {
+	hold, flush := holdUpdates(in, send)
+	err := streamFunc(cf, in, hold)
+	if ferr := flush(); ferr != nil && ferr != io.EOF {
+		log.Printf("error streaming to integration: %v", ferr)
+	}
+	return err
+}
+
+// streamFunc calls cf like callFunc without holding back updates.
+func streamFunc(cf chatfunc, in *botrpc.ChatMessage, send func(*botrpc.ChatMessage) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	if c, ok := connectedBot(cf.Addr); ok {
+		return c.call(ctx, cf, in, send)
+	}
+	// create a connection to the bot
+	conn, err := grpc.Dial(cf.Addr, grpc.WithInsecure())
+	if err != nil {
+		return fmt.Errorf("connecting with client: %v", err)
+	}
+	defer conn.Close()
+	c := botrpc.NewBotFuncsClient(conn)
+
+	// set the FuncName and send it to the bot.
+	in.FuncName = cf.FuncName
+	stream, err := c.SendMessage(ctx, adaptForFunc(cf, in))
+	if err != nil {
+		return fmt.Errorf("calling BotFuncs: %v", err)
+	}
+	for {
+		// read response from bot
+		out, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("streaming from BotFuncs: %v", err)
+		}
+		if err := send(out); err == io.EOF {
+			return nil
+		} else if err != nil {
+			log.Printf("error streaming to integration: %v", err)
+			return nil
+		}
+	}
+}
+
+// sendError lets the user who sent in know that funcName failed.
+func sendError(in *botrpc.ChatMessage, outStream botrpc.Bot_SendMessageServer, funcName string) {
+	sendNotice(in, outStream, fmt.Sprintf("sorry, %v failed to respond.", funcName))
+}
+
+// sendNotice responds to in with body. The message is ephemeral so the rest of
+// the channel isn't bothered with it.
+func sendNotice(in *botrpc.ChatMessage, outStream botrpc.Bot_SendMessageServer, body string) {
+	cm := &botrpc.ChatMessage{
+		Body:       body,
+		Channel:    in.Channel,
+		Visibility: botrpc.ChatMessage_EPHEMERAL,
+	}
+	if err := respond(in, cm, outStream); err != nil {
+		log.Printf("error streaming to integration: %v", err)
+	}
+}
+
+// respond sends out, the response to in, through the outbound middleware and
+// back to the integration on outStream, adapted to what the integration can
+// display. Responses the bot addressed to another
+// integration, or any response if outStream is nil, are delivered to the
+// subscribed integrations instead.
+func respond(in, out *botrpc.ChatMessage, outStream botrpc.Bot_SendMessageServer) error {
+	replyTo(in, out)
+	if out = middleware.outbound(in, out); out == nil {
+		return nil
+	}
+	if outStream == nil || !sameIntegration(in.Source, out.Source) {
+		if err := deliver(out); err != nil {
+			log.Printf("error delivering to %v: %v", out.Source, err)
+		}
+		return nil
+	}
+	i, _ := integration(in.Source)
+	if out = adaptForIntegration(i, out); out == nil {
+		return nil
+	}
+	return outStream.Send(out)
+}
+
+// replyTo fills in the source, channel, thread and user the response belongs
+// to when the bot didn't set them, so replies end up in the thread the message
+// was sent from and ephemeral responses go to the user that sent it.
+func replyTo(in, out *botrpc.ChatMessage) {
+	if out.Source == "" {
+		out.Source = in.Source
+	}
+	if out.Channel == "" {
+		out.Channel = in.Channel
+	}
+	if out.User == "" {
+		out.User = in.User
+	}
+	if out.Channel != in.Channel || !sameIntegration(in.Source, out.Source) {
+		return
+	}
+	if out.ThreadId == "" {
+		out.ThreadId = in.ThreadId
+	}
+	if out.MessageId == "" {
+		out.MessageId = in.MessageId
+	}
+}
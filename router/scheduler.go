@@ -0,0 +1,195 @@
+package router
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// schedule is a botrpc.Schedule with the parsed cron expression and timezone.
+type schedule struct {
+	botrpc.Schedule
+	spec cronSpec
+	loc  *time.Location
+}
+
+// newSchedule parses the cron expression and timezone of s.
+func newSchedule(s *botrpc.Schedule) (schedule, error) {
+	spec, err := parseCron(s.Cron)
+	if err != nil {
+		return schedule{}, err
+	}
+	loc := time.UTC
+	if s.Timezone != "" {
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return schedule{}, err
+		}
+	}
+	if s.Channel == "" {
+		return schedule{}, fmt.Errorf("schedule %q needs a channel", s.Cron)
+	}
+	return schedule{Schedule: *s, spec: spec, loc: loc}, nil
+}
+
+// runScheduler calls the scheduled funcs at the start of every minute that
+// matches one of their schedules. Only one replica of a func is called, and
+// in a cluster only by one instance. It never returns.
+func runScheduler() {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		time.Sleep(next.Sub(now))
+		for _, pool := range pools(funcs()) {
+			for _, s := range pool[0].schedules {
+				if s.spec.matches(next.In(s.loc)) {
+					go runScheduled(pool, s, next)
+				}
+			}
+		}
+	}
+}
+
+// scheduleKey identifies the firing of the schedule s of cf at t across the
+// instances of a cluster.
+func scheduleKey(cf chatfunc, s schedule, t time.Time) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "schedule %q %q %q %q %q %q %d", cf.replicaKey(), s.Cron, s.Timezone, s.Source, s.Channel, s.Body, t.Unix())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// runScheduled calls a replica in pool with a message created from s and
+// delivers the responses to the integrations, unless another instance of the
+// cluster claimed the firing at t.
+func runScheduled(pool []chatfunc, s schedule, t time.Time) {
+	if !claimKeyed(scheduleKey(pool[0], s, t)) {
+		return
+	}
+	in := &botrpc.ChatMessage{
+		Body:    s.Body,
+		Channel: s.Channel,
+		Source:  s.Source,
+	}
+	start := time.Now()
+	err := callPool(pool, in, func(cf chatfunc, out *botrpc.ChatMessage) error {
+		return respond(in, out, nil)
+	})
+	var errs []error
+	if err != nil {
+		log.Printf("error calling scheduled %v: %v", pool[0].FuncName, err)
+		errs = append(errs, fmt.Errorf("%v: %v", pool[0].qualifiedName(), err))
+	}
+	recordAudit(in, []string{pool[0].qualifiedName()}, start, errs)
+}
+
+// cronSpec is a parsed cron expression. Each field is a bit set of the values
+// that match.
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	// if both day fields are restricted a time matches when either one does.
+	domStar, dowStar bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a standard five field cron expression. Fields can be *,
+// numbers, ranges, lists and steps like "*/15" or "1-5".
+func parseCron(expr string) (cronSpec, error) {
+	if m, ok := cronMacros[expr]; ok {
+		expr = m
+	}
+	f := strings.Fields(expr)
+	if len(f) != 5 {
+		return cronSpec{}, fmt.Errorf("cron expression %q needs 5 fields", expr)
+	}
+	var c cronSpec
+	var err error
+	if c.minute, err = parseCronField(f[0], 0, 59); err != nil {
+		return cronSpec{}, err
+	}
+	if c.hour, err = parseCronField(f[1], 0, 23); err != nil {
+		return cronSpec{}, err
+	}
+	if c.dom, err = parseCronField(f[2], 1, 31); err != nil {
+		return cronSpec{}, err
+	}
+	if c.month, err = parseCronField(f[3], 1, 12); err != nil {
+		return cronSpec{}, err
+	}
+	if c.dow, err = parseCronField(f[4], 0, 7); err != nil {
+		return cronSpec{}, err
+	}
+	// sunday is both 0 and 7.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	// like in cron, day fields starting with * such as "*/2" count as
+	// unrestricted.
+	c.domStar, c.dowStar = strings.HasPrefix(f[2], "*"), strings.HasPrefix(f[4], "*")
+	return c, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("bad step in cron field %q", field)
+			}
+			part = part[:i]
+		}
+		lo, hi := min, max
+		if part != "*" {
+			r := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(r[0]); err != nil {
+				return 0, fmt.Errorf("bad value in cron field %q", field)
+			}
+			hi = lo
+			if len(r) == 2 {
+				if hi, err = strconv.Atoi(r[1]); err != nil {
+					return 0, fmt.Errorf("bad range in cron field %q", field)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("cron field %q out of range %d-%d", field, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// matches reports whether t, truncated to the minute, matches the spec.
+func (c cronSpec) matches(t time.Time) bool {
+	if c.minute&(1<<uint(t.Minute())) == 0 ||
+		c.hour&(1<<uint(t.Hour())) == 0 ||
+		c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	bad := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@sometimes",
+	}
+	for _, expr := range bad {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	// 2017-01-02 is a monday.
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2017, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr  string
+		t     time.Time
+		match bool
+	}{
+		{"* * * * *", at(1, 2, 3, 4), true},
+		{"30 9 * * *", at(1, 2, 9, 30), true},
+		{"30 9 * * *", at(1, 2, 9, 31), false},
+		// ranges, lists and steps.
+		{"0 9-17 * * *", at(1, 2, 17, 0), true},
+		{"0 9-17 * * *", at(1, 2, 18, 0), false},
+		{"0,15,45 * * * *", at(1, 2, 3, 45), true},
+		{"0,15,45 * * * *", at(1, 2, 3, 30), false},
+		{"*/15 * * * *", at(1, 2, 3, 30), true},
+		{"*/15 * * * *", at(1, 2, 3, 20), false},
+		{"5/15 * * * *", at(1, 2, 3, 50), true},
+		{"5/15 * * * *", at(1, 2, 3, 0), false},
+		{"0-30/10 * * * *", at(1, 2, 3, 30), true},
+		{"0-30/10 * * * *", at(1, 2, 3, 40), false},
+		{"0 0 1 */3 *", at(4, 1, 0, 0), true},
+		{"0 0 1 */3 *", at(2, 1, 0, 0), false},
+		// weekdays, sunday is both 0 and 7.
+		{"0 9 * * 1-5", at(1, 2, 9, 0), true},
+		{"0 9 * * 1-5", at(1, 1, 9, 0), false},
+		{"0 9 * * 0", at(1, 1, 9, 0), true},
+		{"0 9 * * 7", at(1, 1, 9, 0), true},
+		{"0 9 * * 5-7", at(1, 1, 9, 0), true},
+		{"0 9 * * 7", at(1, 2, 9, 0), false},
+		// with both day fields restricted either one matches.
+		{"0 0 13 * 5", at(1, 13, 0, 0), true},  // friday the 13th
+		{"0 0 13 * 5", at(2, 13, 0, 0), true},  // the 13th, a monday
+		{"0 0 13 * 5", at(1, 6, 0, 0), true},   // a friday
+		{"0 0 13 * 5", at(1, 12, 0, 0), false}, // neither
+		// with one of them unrestricted both have to match.
+		{"0 0 13 * *", at(1, 12, 0, 0), false},
+		{"0 0 * * 5", at(1, 12, 0, 0), false},
+		{"0 0 */2 * 1", at(1, 9, 0, 0), true},
+		{"0 0 */2 * 1", at(1, 2, 0, 0), false}, // a monday on an even day
+		{"0 0 */2 * 1", at(1, 3, 0, 0), false}, // an odd day that isn't a monday
+		// macros.
+		{"@daily", at(1, 2, 0, 0), true},
+		{"@daily", at(1, 2, 1, 0), false},
+		{"@weekly", at(1, 1, 0, 0), true},
+		{"@hourly", at(1, 2, 5, 0), true},
+		{"@yearly", at(1, 1, 0, 0), true},
+		{"@yearly", at(2, 1, 0, 0), false},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr)
+		if err != nil {
+			t.Errorf("parseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.matches(tt.t); got != tt.match {
+			t.Errorf("%q matches %v = %v, want %v", tt.expr, tt.t.Format("Mon Jan 2 15:04"), got, tt.match)
+		}
+	}
+}
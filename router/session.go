@@ -0,0 +1,148 @@
+package router
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// defaultSessionTimeout is used when a bot opens a session without a timeout.
+const defaultSessionTimeout = 5 * time.Minute
+
+// sessionKey scopes a session to a user in a channel.
+type sessionKey struct {
+	source, channel, user string
+}
+
+// session is an open conversation between a user and a func.
+type session struct {
+	cf      chatfunc
+	timeout time.Duration
+	expires time.Time
+}
+
+// sessions contains the open sessions.
+var sessions = struct {
+	sync.Mutex
+	m map[sessionKey]*session
+}{m: make(map[sessionKey]*session)}
+
+func keyOf(in *botrpc.ChatMessage) sessionKey {
+	return sessionKey{source: in.Source, channel: in.Channel, user: in.User}
+}
+
+// activeSession returns the func that has an open session with the sender of
+// in. Expired sessions and sessions whose func was removed are removed. If
+// the replica of the session is gone, another replica of the func takes over.
+func activeSession(in *botrpc.ChatMessage) (chatfunc, bool) {
+	if in.Reaction != nil || in.Event != nil {
+		return chatfunc{}, false
+	}
+	fs := funcs()
+	sessions.Lock()
+	defer sessions.Unlock()
+	k := keyOf(in)
+	s, ok := sessions.m[k]
+	if !ok {
+		return chatfunc{}, false
+	}
+	if time.Now().After(s.expires) {
+		delete(sessions.m, k)
+		return chatfunc{}, false
+	}
+	cf, ok := lookupReplica(fs, s.cf)
+	if !ok {
+		delete(sessions.m, k)
+		return chatfunc{}, false
+	}
+	s.cf = cf
+	s.expires = time.Now().Add(s.timeout)
+	return s.cf, true
+}
+
+// lookupReplica returns the func in fs that is the same as cf, or else
+// another replica of it.
+func lookupReplica(fs []chatfunc, cf chatfunc) (chatfunc, bool) {
+	var replica chatfunc
+	found := false
+	for _, f := range fs {
+		if f.replicaKey() != cf.replicaKey() {
+			continue
+		}
+		if f.Addr == cf.Addr {
+			return f, true
+		}
+		if !found {
+			replica, found = f, true
+		}
+	}
+	return replica, found
+}
+
+// endSessions ends the sessions with funcs that have no replica left in fs.
+func endSessions(fs []chatfunc) {
+	sessions.Lock()
+	defer sessions.Unlock()
+	for k, s := range sessions.m {
+		if _, ok := lookupReplica(fs, s.cf); !ok {
+			delete(sessions.m, k)
+		}
+	}
+}
+
+// updateSession opens, extends or ends the session between the sender of in
+// and cf according to the session of the response.
+func updateSession(in *botrpc.ChatMessage, cf chatfunc, out *botrpc.Session) {
+	if out == nil {
+		return
+	}
+	sessions.Lock()
+	defer sessions.Unlock()
+	k := keyOf(in)
+	if out.Action == botrpc.Session_END {
+		delete(sessions.m, k)
+		return
+	}
+	timeout := defaultSessionTimeout
+	if out.Timeout > 0 {
+		timeout = time.Duration(out.Timeout) * time.Second
+	}
+	sessions.m[k] = &session{cf: cf, timeout: timeout, expires: time.Now().Add(timeout)}
+}
+
+// handleSession sends in straight to the func with the open session. If the
+// user typed cancel the func is told the session ended.
+func handleSession(cf chatfunc, in *botrpc.ChatMessage, outStream botrpc.Bot_SendMessageServer) error {
+	cancel := strings.ToLower(strings.TrimSpace(in.Body)) == "cancel"
+	in.Session = &botrpc.Session{}
+	if cancel {
+		in.Session.Action = botrpc.Session_END
+		updateSession(in, cf, in.Session)
+	}
+	start := time.Now()
+	err := callFunc(cf, in, func(out *botrpc.ChatMessage) error {
+		if !cancel {
+			updateSession(in, cf, out.Session)
+		}
+		return respond(in, out, outStream)
+	})
+	var errs []error
+	if err != nil {
+		log.Printf("error calling %v: %v", cf.FuncName, err)
+		sendError(in, outStream, cf.FuncName)
+		errs = append(errs, fmt.Errorf("%v: %v", cf.qualifiedName(), err))
+	}
+	recordAudit(in, []string{cf.qualifiedName()}, start, errs)
+	if cancel {
+		cm := &botrpc.ChatMessage{
+			Body:       fmt.Sprintf("ok, cancelled %v.", cf.FuncName),
+			Visibility: botrpc.ChatMessage_EPHEMERAL,
+		}
+		return respond(in, cm, outStream)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package router
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// storageServer implements botrpc.StorageServer on top of a fileStore.
+type storageServer struct {
+	store *fileStore
+}
+
+// namespace returns the storage namespace of the bot the token belongs to.
+// Every instance of a cluster has its own storage, so tokens issued by
+// another instance are refused instead of silently using a different store.
+func namespace(token string) (string, error) {
+	ti, ok := lookupToken(token)
+	if !ok {
+		return "", grpc.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	if ti.instance != "" {
+		return "", grpc.Errorf(codes.FailedPrecondition, "storage isn't shared in a cluster, use the instance %v that issued the token", ti.instance)
+	}
+	return ti.namespace, nil
+}
+
+func (s *storageServer) Get(ctx context.Context, in *botrpc.StorageKey) (*botrpc.StorageItem, error) {
+	ns, err := namespace(in.Token)
+	if err != nil {
+		return nil, err
+	}
+	it, ok := s.store.get(ns, in.Key)
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "key %q not found", in.Key)
+	}
+	return it.toProto(in.Key), nil
+}
+
+func (s *storageServer) Set(ctx context.Context, in *botrpc.StorageItem) (*botrpc.StorageItem, error) {
+	ns, err := namespace(in.Token)
+	if err != nil {
+		return nil, err
+	}
+	it := storeItem{Value: in.Value, Expires: expires(in.Ttl)}
+	if err := s.store.set(ns, in.Key, it); err != nil {
+		return nil, err
+	}
+	return it.toProto(in.Key), nil
+}
+
+func (s *storageServer) Delete(ctx context.Context, in *botrpc.StorageKey) (*botrpc.StorageItem, error) {
+	ns, err := namespace(in.Token)
+	if err != nil {
+		return nil, err
+	}
+	it, ok, err := s.store.delete(ns, in.Key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "key %q not found", in.Key)
+	}
+	return it.toProto(in.Key), nil
+}
+
+func (s *storageServer) List(in *botrpc.StorageKey, stream botrpc.Storage_ListServer) error {
+	ns, err := namespace(in.Token)
+	if err != nil {
+		return err
+	}
+	for _, it := range s.store.list(ns, in.Key) {
+		if err := stream.Send(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *storageServer) Increment(ctx context.Context, in *botrpc.StorageIncrement) (*botrpc.StorageItem, error) {
+	ns, err := namespace(in.Token)
+	if err != nil {
+		return nil, err
+	}
+	it, err := s.store.increment(ns, in.Key, in.Delta, in.Ttl)
+	if err != nil {
+		return nil, err
+	}
+	return it.toProto(in.Key), nil
+}
+
+// storeItem is a value in the fileStore.
+type storeItem struct {
+	Value   []byte `json:"value"`
+	Expires int64  `json:"expires,omitempty"` // unix seconds, 0 never expires
+}
+
+func (it storeItem) expired(now time.Time) bool {
+	return it.Expires != 0 && it.Expires <= now.Unix()
+}
+
+func (it storeItem) toProto(key string) *botrpc.StorageItem {
+	si := &botrpc.StorageItem{Key: key, Value: it.Value}
+	if it.Expires != 0 {
+		si.Ttl = it.Expires - time.Now().Unix()
+	}
+	return si
+}
+
+// expires converts a ttl in seconds to an expiry time.
+func expires(ttl int64) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return time.Now().Unix() + ttl
+}
+
+// fileStore keeps all namespaces in memory and writes them to a json file
+// after every change.
+type fileStore struct {
+	mu   sync.Mutex
+	path string
+	data map[string]map[string]storeItem
+}
+
+// openStore loads the store at path. A missing file is an empty store.
+func openStore(path string) (*fileStore, error) {
+	s := &fileStore{path: path, data: make(map[string]map[string]storeItem)}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &s.data); err != nil {
+		return nil, err
+	}
+	migrateNamespaces(s.data)
+	return s, nil
+}
+
+// prefixes of the storage namespaces of bots.
+const (
+	botNamespacePrefix  = "bot:"  // funcs registered with a bot, by bot name
+	addrNamespacePrefix = "addr:" // funcs added without a bot, by address
+)
+
+// migrateNamespaces adds addrNamespacePrefix to the namespaces of funcs added
+// without a bot that were stored by routers using plain addresses.
+func migrateNamespaces(data map[string]map[string]storeItem) {
+	for ns, items := range data {
+		if ns == routerNamespace || strings.HasPrefix(ns, botNamespacePrefix) || strings.HasPrefix(ns, addrNamespacePrefix) {
+			continue
+		}
+		delete(data, ns)
+		data[addrNamespacePrefix+ns] = items
+	}
+}
+
+func (s *fileStore) get(ns, key string) (storeItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	it, ok := s.data[ns][key]
+	if !ok || it.expired(time.Now()) {
+		return storeItem{}, false
+	}
+	return it, true
+}
+
+func (s *fileStore) set(ns, key string, it storeItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data[ns] == nil {
+		s.data[ns] = make(map[string]storeItem)
+	}
+	s.data[ns][key] = it
+	return s.save()
+}
+
+func (s *fileStore) delete(ns, key string) (storeItem, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	it, ok := s.data[ns][key]
+	if !ok || it.expired(time.Now()) {
+		return storeItem{}, false, nil
+	}
+	delete(s.data[ns], key)
+	return it, true, s.save()
+}
+
+// list returns the items in ns with keys starting with prefix, sorted by key.
+func (s *fileStore) list(ns, prefix string) []*botrpc.StorageItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var keys []string
+	for k, it := range s.data[ns] {
+		if strings.HasPrefix(k, prefix) && !it.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	items := make([]*botrpc.StorageItem, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, s.data[ns][k].toProto(k))
+	}
+	return items
+}
+
+// increment adds delta to the integer at key. A ttl of 0 keeps the expiry of
+// an existing item.
+func (s *fileStore) increment(ns, key string, delta, ttl int64) (storeItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data[ns] == nil {
+		s.data[ns] = make(map[string]storeItem)
+	}
+	var n int64
+	it, ok := s.data[ns][key]
+	if ok && it.expired(time.Now()) {
+		it, ok = storeItem{}, false
+	}
+	if ok {
+		var err error
+		if n, err = strconv.ParseInt(string(it.Value), 10, 64); err != nil {
+			return storeItem{}, grpc.Errorf(codes.FailedPrecondition, "key %q is not an integer", key)
+		}
+	}
+	it.Value = []byte(strconv.FormatInt(n+delta, 10))
+	if ttl > 0 {
+		it.Expires = expires(ttl)
+	}
+	s.data[ns][key] = it
+	return it, s.save()
+}
+
+// save writes the store to disk, dropping expired items. The caller must hold
+// s.mu.
+func (s *fileStore) save() error {
+	now := time.Now()
+	for _, items := range s.data {
+		for k, it := range items {
+			if it.expired(now) {
+				delete(items, k)
+			}
+		}
+	}
+	b, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
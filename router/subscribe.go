@@ -0,0 +1,229 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// tokenInfo is what a token handed out by Add identifies.
+type tokenInfo struct {
+	funcName string
+	// namespace is the storage namespace of the bot, see storageNamespace.
+	namespace string
+	// instance is the instance of the cluster that issued the token, empty
+	// if this one did.
+	instance string
+}
+
+// tokens contains the tokens handed out by Add.
+var tokens = struct {
+	sync.Mutex
+	m map[string]tokenInfo
+}{m: make(map[string]tokenInfo)}
+
+// storageNamespace is the storage namespace of cf. The replicas of a bot share
+// one, funcs added without a bot share one with the funcs at the same address.
+// The prefixes keep them apart from each other and from routerNamespace.
+func (cf chatfunc) storageNamespace() string {
+	if cf.bot == "" {
+		return addrNamespacePrefix + cf.Addr
+	}
+	return botNamespacePrefix + cf.bot
+}
+
+// newToken creates and stores a token for the func cf.
+func newToken(cf chatfunc) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	t := hex.EncodeToString(b)
+	addToken(t, cf, "")
+	return t, nil
+}
+
+// addToken stores token as the token of cf issued by instance.
+func addToken(token string, cf chatfunc, instance string) {
+	tokens.Lock()
+	tokens.m[token] = tokenInfo{funcName: cf.FuncName, namespace: cf.storageNamespace(), instance: instance}
+	tokens.Unlock()
+}
+
+// revokeToken makes token invalid.
+func revokeToken(token string) {
+	tokens.Lock()
+	delete(tokens.m, token)
+	tokens.Unlock()
+}
+
+// lookupToken returns what token was issued to.
+func lookupToken(token string) (tokenInfo, bool) {
+	tokens.Lock()
+	defer tokens.Unlock()
+	ti, ok := tokens.m[token]
+	return ti, ok
+}
+
+// integrations contains the registered integrations by id.
+var integrations = struct {
+	sync.Mutex
+	m map[string]*botrpc.Integration
+}{m: make(map[string]*botrpc.Integration)}
+
+// subscriber is an integration waiting for messages on ch.
+type subscriber struct {
+	integration *botrpc.Integration
+	ch          chan *botrpc.ChatMessage
+}
+
+// subscribers are the integrations waiting for messages posted by bots.
+var subscribers = struct {
+	sync.Mutex
+	m map[*subscriber]bool
+}{m: make(map[*subscriber]bool)}
+
+// Register assigns the integration an id and stores it. Registering again
+// with the same name and workspace replaces the earlier registration.
+// Integrations speaking an unsupported protocol version are refused.
+func (s *server) Register(ctx context.Context, in *botrpc.Integration) (*botrpc.Integration, error) {
+	if in.Name == "" {
+		return nil, fmt.Errorf("integration needs a name")
+	}
+	if err := checkVersion(in.ProtocolVersion); err != nil {
+		log.Printf("refusing integration %v: %v", in.Name, err)
+		return nil, err
+	}
+	in.ProtocolVersion = negotiate(in.ProtocolVersion)
+	in.Id = in.Name
+	if in.Workspace != "" {
+		in.Id += "/" + in.Workspace
+	}
+	integrations.Lock()
+	integrations.m[in.Id] = in
+	integrations.Unlock()
+	log.Printf("registered integration %v", in.Id)
+	return in, nil
+}
+
+// Post delivers a message from a bot to the integration named by its source
+// after it went through the outbound middleware.
+func (s *server) Post(ctx context.Context, in *botrpc.PostMessage) (*botrpc.FuncStatus, error) {
+	ti, ok := lookupToken(in.Token)
+	if !ok {
+		return &botrpc.FuncStatus{Status: botrpc.FuncStatus_ERROR}, fmt.Errorf("invalid token")
+	}
+	if in.Message == nil || in.Message.Channel == "" {
+		return &botrpc.FuncStatus{Status: botrpc.FuncStatus_ERROR}, fmt.Errorf("message needs a channel")
+	}
+	in.Message.FuncName = ti.funcName
+	m := middleware.outbound(nil, in.Message)
+	if m == nil {
+		// dropped by the middleware.
+		return &botrpc.FuncStatus{Status: botrpc.FuncStatus_OK}, nil
+	}
+	if err := deliver(m); err != nil {
+		return &botrpc.FuncStatus{Status: botrpc.FuncStatus_ERROR}, err
+	}
+	return &botrpc.FuncStatus{Status: botrpc.FuncStatus_OK}, nil
+}
+
+// Subscribe streams messages for the integration until it disconnects.
+// Integrations that haven't called Register are registered first.
+func (s *server) Subscribe(in *botrpc.Integration, stream botrpc.Bot_SubscribeServer) error {
+	if in.Id == "" {
+		var err error
+		if in, err = s.Register(stream.Context(), in); err != nil {
+			return err
+		}
+	}
+	sub := &subscriber{integration: in, ch: make(chan *botrpc.ChatMessage, 16)}
+	subscribers.Lock()
+	subscribers.m[sub] = true
+	subscribers.Unlock()
+	defer func() {
+		subscribers.Lock()
+		delete(subscribers.m, sub)
+		subscribers.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case m := <-sub.ch:
+			if err := stream.Send(m); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// matches reports whether source refers to the integration, either by id or
+// by platform name.
+func (sub *subscriber) matches(source string) bool {
+	return source == sub.integration.Id || source == sub.integration.Name
+}
+
+// sameIntegration reports whether source refers to the integration with id.
+func sameIntegration(id, source string) bool {
+	if id == source {
+		return true
+	}
+	integrations.Lock()
+	defer integrations.Unlock()
+	i, ok := integrations.m[id]
+	return ok && i.Name == source
+}
+
+// deliver sends m to the subscribed integrations its source refers to, adapted
+// to what each of them can display. If the source is empty there must be only
+// one integration subscribed. Messages for integrations that aren't subscribed
+// to this instance are sent to the other instances of the cluster.
+func deliver(m *botrpc.ChatMessage) error {
+	err := deliverLocal(m)
+	if err != nil && clustered() {
+		if peerErr := deliverPeers(m); peerErr == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// deliverLocal sends m to the integrations subscribed to this instance.
+func deliverLocal(m *botrpc.ChatMessage) error {
+	subscribers.Lock()
+	defer subscribers.Unlock()
+	var subs []*subscriber
+	for sub := range subscribers.m {
+		if m.Source == "" || sub.matches(m.Source) {
+			subs = append(subs, sub)
+		}
+	}
+	switch {
+	case len(subs) == 0 && m.Source == "":
+		return fmt.Errorf("no integrations subscribed")
+	case len(subs) == 0:
+		return fmt.Errorf("no integration %v subscribed", m.Source)
+	case len(subs) > 1 && m.Source == "":
+		return fmt.Errorf("%v integrations subscribed, message needs a source", len(subs))
+	}
+	for _, sub := range subs {
+		am := adaptForIntegration(sub.integration, m)
+		if am == nil {
+			continue
+		}
+		select {
+		case sub.ch <- am:
+		default:
+			log.Printf("dropping message from %v, %v is not keeping up", m.FuncName, sub.integration.Id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package router
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// routerNamespace is the storage namespace the router keeps its own settings
+// in. Bot namespaces start with botNamespacePrefix or addrNamespacePrefix so
+// they can't collide with it.
+const routerNamespace = "chatbot"
+
+// maxSuggestions is the most commands offered for a near miss.
+const maxSuggestions = 3
+
+// addressed reports whether in was directed at the bot, either by the
+// integration or because it starts with the command prefix.
+func addressed(in *botrpc.ChatMessage) bool {
+	if in.Reaction != nil || in.Event != nil {
+		return false
+	}
+	return in.Addressed || strings.HasPrefix(in.Body, config.commandPrefix)
+}
+
+func suggestionsKey(in *botrpc.ChatMessage) string {
+	return "suggestions-off/" + in.Source + "/" + in.Channel
+}
+
+// suggestionsOn reports whether suggestions are enabled in the channel of in.
+func suggestionsOn(in *botrpc.ChatMessage) bool {
+	_, off := store.get(routerNamespace, suggestionsKey(in))
+	return !off
+}
+
+// toggleSuggestions handles the "suggestions on" and "suggestions off"
+// commands. It reports whether in was one of them.
+func toggleSuggestions(in *botrpc.ChatMessage, outStream botrpc.Bot_SendMessageServer) bool {
+	if !addressed(in) {
+		return false
+	}
+	f := strings.Fields(strings.TrimPrefix(strings.ToLower(in.Body), config.commandPrefix))
+	if len(f) != 2 || f[0] != "suggestions" || (f[1] != "on" && f[1] != "off") {
+		return false
+	}
+	var err error
+	if f[1] == "off" {
+		err = store.set(routerNamespace, suggestionsKey(in), storeItem{Value: []byte("off")})
+	} else {
+		_, _, err = store.delete(routerNamespace, suggestionsKey(in))
+	}
+	if err != nil {
+		log.Printf("error saving suggestions setting: %v", err)
+		sendNotice(in, outStream, "sorry, the setting couldn't be saved.")
+		return true
+	}
+	sendNotice(in, outStream, fmt.Sprintf("ok, suggestions are %v in this channel.", f[1]))
+	return true
+}
+
+// suggest replies to an addressed message that triggered nothing with the
+// commands that are closest to it.
+func suggest(in *botrpc.ChatMessage, outStream botrpc.Bot_SendMessageServer) {
+	cmd := strings.ToLower(firstWord(in.Body))
+	if cmd == "" {
+		return
+	}
+	type candidate struct {
+		text string
+		dist int
+	}
+	seen := make(map[string]bool)
+	var cands []candidate
+	add := func(text string) {
+		if text == "" || seen[text] {
+			return
+		}
+		seen[text] = true
+		d := editDistance(cmd, strings.ToLower(firstWord(text)))
+		if d <= maxDistance(cmd) {
+			cands = append(cands, candidate{text: text, dist: d})
+		}
+	}
+	for _, cf := range funcs() {
+		add(config.commandPrefix + cf.FuncName)
+		add(config.commandPrefix + cf.qualifiedName())
+		for _, e := range cf.Examples {
+			add(e)
+		}
+	}
+	if len(cands) == 0 {
+		sendNotice(in, outStream, fmt.Sprintf("sorry, I don't know %q. Try help.", cmd))
+		return
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+	if len(cands) > maxSuggestions {
+		cands = cands[:maxSuggestions]
+	}
+	var s []string
+	for _, c := range cands {
+		s = append(s, fmt.Sprintf("%q", c.text))
+	}
+	sendNotice(in, outStream, fmt.Sprintf("sorry, I don't know %q. Did you mean %v?", cmd, strings.Join(s, " or ")))
+}
+
+func firstWord(s string) string {
+	f := strings.Fields(s)
+	if len(f) == 0 {
+		return ""
+	}
+	return f[0]
+}
+
+// maxDistance is the largest edit distance from cmd that is still considered
+// a near miss.
+func maxDistance(cmd string) int {
+	if d := len(cmd) / 3; d > 2 {
+		return d
+	}
+	return 2
+}
+
+// editDistance is the levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
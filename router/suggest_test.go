@@ -0,0 +1,30 @@
+package router
+
+import "testing"
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		d    int
+	}{
+		{"", "", 0},
+		{"deploy", "deploy", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"deploy", "deplyo", 2},
+		{"deploy", "depoy", 1},
+		{"deploy", "deployy", 1},
+		{"deploy", "dxploy", 1},
+		{"kitten", "sitting", 3},
+		{"café", "cafe", 1},
+		{"status", "stats", 1},
+	}
+	for _, tt := range tests {
+		if d := editDistance(tt.a, tt.b); d != tt.d {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, d, tt.d)
+		}
+		if d := editDistance(tt.b, tt.a); d != tt.d {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, d, tt.d)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package router
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// transcriptLog appends a botrpc.TranscriptEntry as a JSON line for every
+// message from an integration. The replay command reads it.
+type transcriptLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// transcript is the transcript configured with CHATBOT_TRANSCRIPT_FILE. It
+// is nil when recording is off.
+var transcript *transcriptLog
+
+func openTranscript(path string) (*transcriptLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &transcriptLog{f: f}, nil
+}
+
+func (t *transcriptLog) append(e *botrpc.TranscriptEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err = t.f.Write(append(b, '\n'))
+	return err
+}
+
+// recordingStream keeps a copy of every response sent on the stream.
+type recordingStream struct {
+	botrpc.Bot_SendMessageServer
+	mu        sync.Mutex
+	responses []*botrpc.ChatMessage
+}
+
+func (r *recordingStream) Send(m *botrpc.ChatMessage) error {
+	r.mu.Lock()
+	r.responses = append(r.responses, proto.Clone(m).(*botrpc.ChatMessage))
+	r.mu.Unlock()
+	return r.Bot_SendMessageServer.Send(m)
+}
+
+// handleRecorded handles in like handleChat and records it with the responses
+// sent back on stream. Responses delivered to other integrations aren't part
+// of the transcript.
+func handleRecorded(in *botrpc.ChatMessage, stream botrpc.Bot_SendMessageServer) error {
+	e := &botrpc.TranscriptEntry{
+		Time:    time.Now().UnixNano(),
+		Message: proto.Clone(in).(*botrpc.ChatMessage),
+	}
+	rs := &recordingStream{Bot_SendMessageServer: stream}
+	err := handleChat(in, rs)
+	e.Responses = rs.responses
+	if err := transcript.append(e); err != nil {
+		log.Printf("error writing transcript: %v", err)
+	}
+	return err
+}
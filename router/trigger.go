@@ -0,0 +1,119 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+// matcher reports whether a message body matches a trigger.
+type matcher func(body string) bool
+
+// compileTrigger returns the matcher for the trigger of f.
+func compileTrigger(f *botrpc.Func) (matcher, error) {
+	t := f.Trigger
+	fold := func(s string) string { return s }
+	if f.IgnoreCase {
+		t = strings.ToLower(t)
+		fold = strings.ToLower
+	}
+	switch f.TriggerType {
+	case botrpc.Func_REGEX:
+		expr := f.Trigger
+		if f.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	case botrpc.Func_COMMAND:
+		return func(body string) bool {
+			body = fold(body)
+			if !strings.HasPrefix(body, t) {
+				return false
+			}
+			r, _ := utf8.DecodeRuneInString(body[len(t):])
+			return len(body) == len(t) || unicode.IsSpace(r)
+		}, nil
+	case botrpc.Func_PREFIX:
+		return func(body string) bool {
+			return strings.HasPrefix(fold(body), t)
+		}, nil
+	case botrpc.Func_KEYWORD:
+		return func(body string) bool {
+			return containsWord(fold(body), t)
+		}, nil
+	case botrpc.Func_GLOB:
+		re, err := regexp.Compile(globExpr(f.Trigger, f.IgnoreCase))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	return nil, fmt.Errorf("unknown trigger type %v", f.TriggerType)
+}
+
+// containsWord reports whether s contains word with a word boundary on both
+// sides, so "hello" matches "hello there" but not "othello".
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for i := 0; i <= len(s)-len(word); {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(s) || !isWordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(s[start:])
+		i = start + size
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// globExpr converts a glob with * and ? wildcards to an anchored regexp.
+func globExpr(glob string, ignoreCase bool) string {
+	var b bytes.Buffer
+	b.WriteString("(?s)")
+	if ignoreCase {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// triggerTypeHelp is how the trigger types are shown in the help output.
+// Regexps are shown without a type like they always have been.
+var triggerTypeHelp = map[botrpc.Func_TriggerType]string{
+	botrpc.Func_COMMAND: "command:",
+	botrpc.Func_PREFIX:  "prefix:",
+	botrpc.Func_KEYWORD: "keyword:",
+	botrpc.Func_GLOB:    "glob:",
+}
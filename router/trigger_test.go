@@ -0,0 +1,80 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/foolusion/chatbot/botrpc"
+)
+
+func TestCompileTrigger(t *testing.T) {
+	tests := []struct {
+		typ        botrpc.Func_TriggerType
+		trigger    string
+		ignoreCase bool
+		body       string
+		match      bool
+	}{
+		{botrpc.Func_REGEX, "^!ping$", false, "!ping", true},
+		{botrpc.Func_REGEX, "ping", false, "say ping please", true},
+		{botrpc.Func_REGEX, "ping", false, "PING", false},
+		{botrpc.Func_REGEX, "ping", true, "PING", true},
+
+		{botrpc.Func_COMMAND, "!deploy", false, "!deploy", true},
+		{botrpc.Func_COMMAND, "!deploy", false, "!deploy prod", true},
+		{botrpc.Func_COMMAND, "!deploy", false, "!deploy\tprod", true},
+		{botrpc.Func_COMMAND, "!deploy", false, "!deployment", false},
+		{botrpc.Func_COMMAND, "!deploy", false, "please !deploy", false},
+		{botrpc.Func_COMMAND, "!deploy", false, "!Deploy prod", false},
+		{botrpc.Func_COMMAND, "!deploy", true, "!DEPLOY prod", true},
+		{botrpc.Func_COMMAND, "!Deploy", true, "!deploy", true},
+
+		{botrpc.Func_PREFIX, "!log", false, "!logs api", true},
+		{botrpc.Func_PREFIX, "!log", false, " !log", false},
+		{botrpc.Func_PREFIX, "!log", true, "!LOG", true},
+
+		{botrpc.Func_KEYWORD, "hello", false, "hello", true},
+		{botrpc.Func_KEYWORD, "hello", false, "well hello there", true},
+		{botrpc.Func_KEYWORD, "hello", false, "hello, world", true},
+		{botrpc.Func_KEYWORD, "hello", false, "say hello!", true},
+		{botrpc.Func_KEYWORD, "hello", false, "othello", false},
+		{botrpc.Func_KEYWORD, "hello", false, "hellos", false},
+		{botrpc.Func_KEYWORD, "hello", false, "hello_world", false},
+		{botrpc.Func_KEYWORD, "hello", false, "othello hello", true},
+		{botrpc.Func_KEYWORD, "hello", false, "Hello", false},
+		{botrpc.Func_KEYWORD, "hello", true, "Hello", true},
+		{botrpc.Func_KEYWORD, "good morning", true, "Good Morning all", true},
+		{botrpc.Func_KEYWORD, "café", false, "un café noir", true},
+		{botrpc.Func_KEYWORD, "café", false, "cafés", false},
+
+		{botrpc.Func_GLOB, "deploy * to prod", false, "deploy api to prod", true},
+		{botrpc.Func_GLOB, "deploy * to prod", false, "deploy api to prod now", false},
+		{botrpc.Func_GLOB, "deploy * to prod", false, "please deploy api to prod", false},
+		{botrpc.Func_GLOB, "v?.0", false, "v2.0", true},
+		{botrpc.Func_GLOB, "v?.0", false, "v2x0", false},
+		{botrpc.Func_GLOB, "v?.0", false, "v10.0", false},
+		{botrpc.Func_GLOB, "*", false, "line one\nline two", true},
+		{botrpc.Func_GLOB, "DEPLOY *", false, "deploy api", false},
+		{botrpc.Func_GLOB, "DEPLOY *", true, "deploy api", true},
+	}
+	for _, tt := range tests {
+		m, err := compileTrigger(&botrpc.Func{Trigger: tt.trigger, TriggerType: tt.typ, IgnoreCase: tt.ignoreCase})
+		if err != nil {
+			t.Errorf("compileTrigger(%v %q): %v", tt.typ, tt.trigger, err)
+			continue
+		}
+		if got := m(tt.body); got != tt.match {
+			t.Errorf("%v %q (ignore case %v) matches %q = %v, want %v", tt.typ, tt.trigger, tt.ignoreCase, tt.body, got, tt.match)
+		}
+	}
+}
+
+func TestCompileTriggerErrors(t *testing.T) {
+	for _, f := range []*botrpc.Func{
+		{Trigger: "(", TriggerType: botrpc.Func_REGEX},
+		{Trigger: "x", TriggerType: botrpc.Func_TriggerType(99)},
+	} {
+		if _, err := compileTrigger(f); err == nil {
+			t.Errorf("compileTrigger(%v %q) succeeded, want an error", f.TriggerType, f.Trigger)
+		}
+	}
+}